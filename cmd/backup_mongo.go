@@ -2,18 +2,16 @@
 package cmd
 
 import (
-	// ... سایر import ها مثل قبل ...
-	"bufio"
+	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"runtime"
-	"time"
+	"strings"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/mongodb"
+	"github.com/mshamsi502/dataweaver-cli/internal/redact"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // نام متغیر به backupMongoCmd تغییر کرد
@@ -22,76 +20,146 @@ var backupMongoCmd = &cobra.Command{
 	Short: "Backup a MongoDB database",
 	Long: `Creates a compressed archive of a remote MongoDB database using mongodump.
 It reads the required configurations (remote URI, tool path, backup path)
-from the application's config file.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		// ... محتوای تابع Run دقیقاً مثل قبل باقی می‌ماند ...
-		fmt.Println("Starting MongoDB backup...")
-
-		remoteURI := viper.GetString("mongodb.remote_uri")
-		toolsPath := viper.GetString("paths.mongo_tools")
-		backupDir := viper.GetString("paths.backup")
-
-		if remoteURI == "" || toolsPath == "" || backupDir == "" {
-			log.Fatal("Configuration error: 'mongodb.remote_uri', 'paths.mongo_tools', and 'paths.backup' must be set. Please run 'dataweaver-cli configure' first.")
+from the application's config file.
+
+By default the whole deployment behind 'mongodb.remote_uri' is dumped. Use --db,
+--collection, --exclude-collection and --exclude-collections-with-prefix (or the
+matching 'mongodb.backup.*' config keys) to back up only part of it.
+
+For point-in-time restores of a replica set, take snapshots with --oplog (or
+'mongodb.backup.oplog: true') and archive the oplog in between with --incremental.
+'restore mongo --until <timestamp>' restores the nearest snapshot and replays the
+oplog up to that time.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := operationContext(cmd)
+		defer cancel()
+		run := backupMongo
+		if incremental, _ := cmd.Flags().GetBool("incremental"); incremental {
+			run = backupMongoOplog
 		}
-
-		fmt.Printf("Using remote URI: %s\n", remoteURI)
-		fmt.Printf("Path to MongoDB tools: %s\n", toolsPath)
-		fmt.Printf("Backup destination directory: %s\n", backupDir)
-
-		mongoDumpExecutable := "mongodump"
-		if runtime.GOOS == "windows" {
-			mongoDumpExecutable = "mongodump.exe"
+		result, err := run(ctx, cmd)
+		if err != nil {
+			return err
 		}
-		mongoDumpPath := filepath.Join(toolsPath, mongoDumpExecutable)
+		setResult(result)
+		return nil
+	},
+}
 
-		if _, err := os.Stat(mongoDumpPath); os.IsNotExist(err) {
-			log.Fatalf("mongodump not found at the specified path: %s. Please verify your 'paths.mongo_tools' configuration.", mongoDumpPath)
-		}
+// backupMongo runs a MongoDB backup of the active profile with the flags of cmd and
+// returns its result. It is shared by 'backup mongo' and the daemon.
+func backupMongo(ctx context.Context, cmd *cobra.Command) (*backupResult, error) {
+	fmt.Fprintln(humanOutput, "Starting MongoDB backup...")
+	if profile := config.ActiveProfile(); profile != "" {
+		fmt.Fprintf(humanOutput, "Using profile: %s\n", profile)
+	}
+
+	remoteURI, backupDir, err := mongoBackupConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	selection := mongoSelectionFromFlags(cmd)
+	if err := selection.Validate(); err != nil {
+		return nil, withCode(codeUsage, fmt.Errorf("invalid namespace selection: %w", err))
+	}
+	oplog := boolFlagOrConfig(cmd, "oplog", "mongodb.backup.oplog")
+	if oplog && selection.Database != "" {
+		return nil, withCode(codeUsage, errors.New("--oplog requires a backup of the whole deployment; remove --db (or 'mongodb.backup.db')"))
+	}
+
+	fmt.Fprintf(humanOutput, "Using remote URI: %s\n", redact.String(remoteURI))
+	toolsPath, err := findMongoTools()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(humanOutput, "Backup destination directory: %s\n", backupDir)
+	fmt.Fprintf(humanOutput, "Namespaces: %s\n", strings.Join(selection.Namespaces(), ", "))
+	if excluded := selection.ExcludedNamespaces(); len(excluded) > 0 {
+		fmt.Fprintf(humanOutput, "Excluded namespaces: %s\n", strings.Join(excluded, ", "))
+	}
+
+	if oplog {
+		fmt.Fprintln(humanOutput, "The oplog is included for point-in-time restores.")
+	}
+
+	result, err := runBackup(ctx, &mongodb.Engine{ToolsPath: toolsPath, Selection: selection, Oplog: oplog}, remoteURI, backupDir)
+	if err != nil {
+		return nil, err
+	}
+	result.UploadedTo, err = uploadBackupIfRequested(ctx, cmd, result.Archive)
+	return result, err
+}
 
-		timestamp := time.Now().Format("2006-01-02_15-04-05")
-		backupFileName := fmt.Sprintf("backup-%s.gz", timestamp)
-		if err := os.MkdirAll(backupDir, 0755); err != nil {
-			log.Fatalf("Failed to create backup directory '%s': %v", backupDir, err)
-		}
-		backupFilePath := filepath.Join(backupDir, backupFileName)
-		fmt.Printf("Backup file will be saved to: %s\n", backupFilePath)
+// mongoBackupConfig reads the remote URI and the backup directory of MongoDB backups.
+func mongoBackupConfig() (remoteURI, backupDir string, err error) {
+	remoteURI, err = config.GetURI("mongodb.remote_uri")
+	if err != nil {
+		return "", "", withCode(codeConfig, fmt.Errorf("configuration error: %w", err))
+	}
+	backupDir = config.GetString("paths.backup")
+
+	if remoteURI == "" || backupDir == "" {
+		return "", "", withCode(codeConfig, errors.New("configuration error: 'mongodb.remote_uri' and 'paths.backup' must be set. Please run 'dataweaver-cli configure' first"))
+	}
+	return remoteURI, backupDir, nil
+}
 
-		dumpCmd := exec.Command(mongoDumpPath,
-			fmt.Sprintf("--uri=%s", remoteURI),
-			fmt.Sprintf("--archive=%s", backupFilePath),
-			"--gzip",
-		)
+// mongoSelectionFromFlags builds the namespace selection from the flags registered by
+// addMongoSelectionFlags, falling back to the 'mongodb.backup.*' config keys.
+func mongoSelectionFromFlags(cmd *cobra.Command) mongodb.Selection {
+	return mongodb.Selection{
+		Database:                  stringFlagOrConfig(cmd, "db", "mongodb.backup.db"),
+		Collection:                stringFlagOrConfig(cmd, "collection", "mongodb.backup.collection"),
+		ExcludeCollections:        stringSliceFlagOrConfig(cmd, "exclude-collection", "mongodb.backup.exclude_collections"),
+		ExcludeCollectionPrefixes: stringSliceFlagOrConfig(cmd, "exclude-collections-with-prefix", "mongodb.backup.exclude_collection_prefixes"),
+	}
+}
 
-		stdout, err := dumpCmd.StdoutPipe()
-		if err != nil {
-			log.Fatalf("Failed to get stdout pipe: %v", err)
-		}
-		dumpCmd.Stderr = dumpCmd.Stdout
-
-		scanner := bufio.NewScanner(stdout)
-		go func() {
-			for scanner.Scan() {
-				fmt.Printf("  [mongodump]: %s\n", scanner.Text())
-			}
-		}()
-
-		fmt.Println("Executing mongodump command...")
-		if err := dumpCmd.Start(); err != nil {
-			log.Fatalf("Failed to start mongodump command: %v", err)
-		}
+// addMongoSelectionFlags registers the mongodump namespace selection flags.
+func addMongoSelectionFlags(cmd *cobra.Command) {
+	// در صورت عدم استفاده، مقادیر 'mongodb.backup.*' از کانفیگ خوانده می‌شوند
+	cmd.Flags().String("db", "", "Database to back up (default: all databases)")
+	cmd.Flags().String("collection", "", "Collection to back up (requires --db)")
+	cmd.Flags().StringSlice("exclude-collection", nil, "Collection to exclude (repeatable, requires --db)")
+	cmd.Flags().StringSlice("exclude-collections-with-prefix", nil, "Exclude collections whose name starts with this prefix (repeatable, requires --db)")
+}
 
-		err = dumpCmd.Wait()
-		if err != nil {
-			log.Fatalf("mongodump command failed with error: %v", err)
-		}
+// stringFlagOrConfig returns the flag value if it was set explicitly, otherwise the config value.
+func stringFlagOrConfig(cmd *cobra.Command, flag, key string) string {
+	if cmd.Flags().Changed(flag) {
+		value, _ := cmd.Flags().GetString(flag)
+		return value
+	}
+	return config.GetString(key)
+}
 
-		fmt.Println("------------------------")
-		fmt.Println("MongoDB backup completed successfully!")
-	},
+// boolFlagOrConfig is the bool counterpart of stringFlagOrConfig.
+func boolFlagOrConfig(cmd *cobra.Command, flag, key string) bool {
+	if cmd.Flags().Changed(flag) {
+		value, _ := cmd.Flags().GetBool(flag)
+		return value
+	}
+	return config.GetBool(key)
+}
+
+// stringSliceFlagOrConfig is the []string counterpart of stringFlagOrConfig.
+func stringSliceFlagOrConfig(cmd *cobra.Command, flag, key string) []string {
+	if cmd.Flags().Changed(flag) {
+		value, _ := cmd.Flags().GetStringSlice(flag)
+		return value
+	}
+	return config.GetStringSlice(key)
 }
 
 func init() {
 	// این دستور، خودش را به والدش (backupCmd) اضافه می‌کند
 	backupCmd.AddCommand(backupMongoCmd)
+	addUploadFlag(backupMongoCmd)
+	addTimeoutFlag(backupMongoCmd)
+	addMongoSelectionFlags(backupMongoCmd)
+	backupMongoCmd.Flags().Bool("oplog", false, "Include the oplog for point-in-time restores (replica sets only, whole deployment)")
+	backupMongoCmd.Flags().Bool("incremental", false, "Only archive the oplog written since the last --oplog snapshot or incremental backup")
+	backupMongoCmd.MarkFlagsMutuallyExclusive("incremental", "oplog")
+	backupMongoCmd.MarkFlagsMutuallyExclusive("incremental", "db")
 }
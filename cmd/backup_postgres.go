@@ -0,0 +1,83 @@
+// فایل: cmd/backup_postgres.go
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/postgres"
+	"github.com/mshamsi502/dataweaver-cli/internal/redact"
+
+	"github.com/spf13/cobra"
+)
+
+// backupPostgresCmd represents the postgres subcommand of backup
+var backupPostgresCmd = &cobra.Command{
+	Use:   "postgres",
+	Short: "Backup a PostgreSQL database",
+	Long: `Creates a custom-format archive of a remote PostgreSQL database using pg_dump.
+It reads 'postgres.remote_uri', 'paths.backup' and optionally 'paths.postgres_tools'
+from the application's config file. If 'paths.postgres_tools' is empty, pg_dump
+is looked up on PATH.
+
+Use --schema, --table and --exclude-table (or the matching 'postgres.backup.*'
+config keys) to back up only part of the database.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := operationContext(cmd)
+		defer cancel()
+		result, err := backupPostgres(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		setResult(result)
+		return nil
+	},
+}
+
+// backupPostgres runs a PostgreSQL backup of the active profile with the flags of cmd and
+// returns its result. It is shared by 'backup postgres' and the daemon.
+func backupPostgres(ctx context.Context, cmd *cobra.Command) (*backupResult, error) {
+	fmt.Fprintln(humanOutput, "Starting PostgreSQL backup...")
+	if profile := config.ActiveProfile(); profile != "" {
+		fmt.Fprintf(humanOutput, "Using profile: %s\n", profile)
+	}
+
+	remoteURI, err := config.GetURI("postgres.remote_uri")
+	if err != nil {
+		return nil, withCode(codeConfig, fmt.Errorf("configuration error: %w", err))
+	}
+	toolsPath := config.GetString("paths.postgres_tools")
+	backupDir := config.GetString("paths.backup")
+
+	if remoteURI == "" || backupDir == "" {
+		return nil, withCode(codeConfig, errors.New("configuration error: 'postgres.remote_uri' and 'paths.backup' must be set"))
+	}
+
+	eng := &postgres.Engine{
+		ToolsPath:     toolsPath,
+		Schemas:       stringSliceFlagOrConfig(cmd, "schema", "postgres.backup.schemas"),
+		Tables:        stringSliceFlagOrConfig(cmd, "table", "postgres.backup.tables"),
+		ExcludeTables: stringSliceFlagOrConfig(cmd, "exclude-table", "postgres.backup.exclude_tables"),
+	}
+
+	fmt.Fprintf(humanOutput, "Using remote URI: %s\n", redact.String(remoteURI))
+	fmt.Fprintf(humanOutput, "Backup destination directory: %s\n", backupDir)
+	result, err := runBackup(ctx, eng, remoteURI, backupDir)
+	if err != nil {
+		return nil, err
+	}
+	result.UploadedTo, err = uploadBackupIfRequested(ctx, cmd, result.Archive)
+	return result, err
+}
+
+func init() {
+	backupCmd.AddCommand(backupPostgresCmd)
+	addUploadFlag(backupPostgresCmd)
+	addTimeoutFlag(backupPostgresCmd)
+
+	backupPostgresCmd.Flags().StringSlice("schema", nil, "Schema to back up (repeatable, pg_dump pattern)")
+	backupPostgresCmd.Flags().StringSlice("table", nil, "Table to back up (repeatable, pg_dump pattern)")
+	backupPostgresCmd.Flags().StringSlice("exclude-table", nil, "Table to exclude (repeatable, pg_dump pattern)")
+}
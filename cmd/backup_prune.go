@@ -0,0 +1,141 @@
+// فایل: cmd/backup_prune.go
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/backup"
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// backupPruneCmd represents the 'backup prune' subcommand
+var backupPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old backups according to the retention policy",
+	Long: `Applies the grandfather-father-son retention policy from the 'retention' config
+section to the archives in the backup directory:
+
+  retention:
+    keep_last: 3        # the 3 most recent backups
+    keep_daily: 7       # one backup per day for the last 7 days
+    keep_weekly: 4      # one backup per week for the last 4 weeks
+    keep_monthly: 6     # one backup per month for the last 6 months
+    max_total_size: 20GB
+
+A backup is kept if any rule keeps it; max_total_size then drops the oldest of the
+remaining ones. The archives of each database engine are rotated separately. The
+same policy is applied automatically after every backup.
+Incremental MongoDB oplog archives are deleted once they are older than the oldest
+remaining snapshot taken with --oplog.
+Only archives named 'backup-<timestamp><ext>' are deleted; other files in the
+backup directory are never touched.
+Use --dry-run to only list what would be deleted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		backupDir := config.GetString("paths.backup")
+		if backupDir == "" {
+			return errorf(codeConfig, "configuration error: 'paths.backup' must be set. Please run 'dataweaver-cli configure' first")
+		}
+
+		policy, err := retentionPolicyFromConfig()
+		if err != nil {
+			return errorf(codeConfig, "configuration error: %w", err)
+		}
+		results := []*pruneResult{}
+		if policy.IsZero() {
+			fmt.Fprintln(humanOutput, "No retention policy configured ('retention.*'). Nothing to prune.")
+			setResult(results)
+			return nil
+		}
+
+		// هر موتور پایگاه داده جداگانه چرخش داده می‌شود
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		for _, eng := range registeredEngines {
+			fmt.Fprintf(humanOutput, "[%s]\n", eng.Name())
+			result, err := pruneBackups(backupDir, eng.Extension(), policy, dryRun)
+			if err != nil {
+				return fmt.Errorf("prune failed: %w", err)
+			}
+			result.Engine = eng.Name()
+			results = append(results, result)
+		}
+		// آرشیوهای oplog قدیمی‌تر از قدیمی‌ترین snapshot باقیمانده قابل استفاده نیستند
+		fmt.Fprintln(humanOutput, "[mongodb-oplog]")
+		result, err := pruneOplogSlices(backupDir, dryRun)
+		if err != nil {
+			return fmt.Errorf("prune failed: %w", err)
+		}
+		results = append(results, result)
+		setResult(results)
+		return nil
+	},
+}
+
+// pruneResult is the outcome of pruning the archives of one engine, reported by '--output json'.
+type pruneResult struct {
+	Engine     string   `json:"engine"`
+	DryRun     bool     `json:"dry_run"`
+	Deleted    []string `json:"deleted"`
+	Kept       int      `json:"kept"`
+	FreedBytes int64    `json:"freed_bytes"`
+}
+
+// retentionPolicyFromConfig reads the 'retention.*' keys.
+func retentionPolicyFromConfig() (backup.RetentionPolicy, error) {
+	maxSize, err := backup.ParseSize(config.GetString("retention.max_total_size"))
+	if err != nil {
+		return backup.RetentionPolicy{}, fmt.Errorf("retention.max_total_size: %w", err)
+	}
+	return backup.RetentionPolicy{
+		KeepLast:     config.GetInt("retention.keep_last"),
+		KeepDaily:    config.GetInt("retention.keep_daily"),
+		KeepWeekly:   config.GetInt("retention.keep_weekly"),
+		KeepMonthly:  config.GetInt("retention.keep_monthly"),
+		MaxTotalSize: maxSize,
+	}, nil
+}
+
+// pruneBackups deletes the archives (and their manifests) in backupDir that the policy does not keep.
+// The archives deleted so far are listed in the result even if an error is returned.
+func pruneBackups(backupDir, ext string, policy backup.RetentionPolicy, dryRun bool) (*pruneResult, error) {
+	result := &pruneResult{DryRun: dryRun, Deleted: []string{}}
+	archives, err := backup.List(backupDir, ext)
+	if err != nil {
+		return result, fmt.Errorf("error reading backup directory '%s': %w", backupDir, err)
+	}
+	keep, remove := policy.Plan(archives)
+	result.Kept = len(keep)
+
+	if len(remove) == 0 {
+		fmt.Fprintf(humanOutput, "Retention: keeping all %d backup(s), nothing to delete.\n", len(keep))
+		return result, nil
+	}
+
+	var freed int64
+	for _, a := range remove {
+		if dryRun {
+			fmt.Fprintf(humanOutput, "  would delete: %s (%s)\n", a.Name, backup.FormatSize(a.Size))
+		} else {
+			if err := backup.Remove(a); err != nil {
+				return result, fmt.Errorf("deleting '%s': %w", a.Path, err)
+			}
+			fmt.Fprintf(humanOutput, "  deleted: %s (%s)\n", a.Name, backup.FormatSize(a.Size))
+		}
+		freed += a.Size
+		result.FreedBytes = freed
+		result.Deleted = append(result.Deleted, a.Name)
+	}
+
+	if dryRun {
+		fmt.Fprintf(humanOutput, "Retention (dry run): %d backup(s) would be deleted, %s would be freed, %d kept.\n", len(remove), backup.FormatSize(freed), len(keep))
+	} else {
+		fmt.Fprintf(humanOutput, "Retention: deleted %d backup(s), freed %s, %d kept.\n", len(remove), backup.FormatSize(freed), len(keep))
+	}
+	return result, nil
+}
+
+func init() {
+	backupCmd.AddCommand(backupPruneCmd)
+	backupPruneCmd.Flags().Bool("dry-run", false, "Only list the backups that would be deleted")
+}
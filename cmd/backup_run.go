@@ -0,0 +1,225 @@
+// فایل: cmd/backup_run.go
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/backup"
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+	"github.com/mshamsi502/dataweaver-cli/internal/mongodb"
+	"github.com/mshamsi502/dataweaver-cli/internal/mysql"
+	"github.com/mshamsi502/dataweaver-cli/internal/postgres"
+)
+
+// registeredEngines lists every supported engine. The zero values are only used for
+// engine independent information such as the archive extension.
+var registeredEngines = []engine.Engine{
+	&mongodb.Engine{},
+	&postgres.Engine{},
+	&mysql.Engine{},
+}
+
+// backupResult is the outcome of a backup, reported by '--output json' and the daemon.
+type backupResult struct {
+	Engine          string    `json:"engine"`
+	Archive         string    `json:"archive"`
+	Size            int64     `json:"size"`
+	SHA256          string    `json:"sha256"`
+	Encrypted       bool      `json:"encrypted"`
+	SourceHost      string    `json:"source_host,omitempty"`
+	Namespaces      []string  `json:"namespaces,omitempty"`
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	UploadedTo      string    `json:"uploaded_to,omitempty"`
+}
+
+// runBackup dumps the database behind uri with eng into backupDir, writes the manifest
+// next to the archive and applies the retention policy.
+// The backup directory is locked while the backup runs (see backup.Lock). The archive
+// only appears in backupDir once it is complete: a failed, cancelled or timed out
+// backup (see operationContext) removes what it has written.
+func runBackup(ctx context.Context, eng engine.Engine, uri, backupDir string) (*backupResult, error) {
+	if err := eng.CheckTools(); err != nil {
+		return nil, withCode(codeToolMissing, fmt.Errorf("%w. Please verify your tools path configuration", err))
+	}
+
+	passphrase, err := encryptionPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory '%s': %w", backupDir, err)
+	}
+	unlock, err := backup.Lock(backupDir)
+	if errors.Is(err, backup.ErrLocked) {
+		return nil, withCode(codeBusy, err)
+	} else if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	startedAt := time.Now()
+	backupFileName := backup.ArchiveName(startedAt, archiveExtension(eng, passphrase != ""))
+	backupFilePath := filepath.Join(backupDir, backupFileName)
+	fmt.Fprintf(humanOutput, "Backup file will be saved to: %s\n", backupFilePath)
+	if passphrase != "" {
+		fmt.Fprintln(humanOutput, "The archive is encrypted while it is written.")
+	}
+
+	// آرشیو ابتدا در فایل موقت ".part" نوشته می‌شود و فقط در صورت موفقیت جایگزین می‌شود
+	archive, err := backup.Create(backupFilePath, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create '%s': %w", backupFilePath, err)
+	}
+	defer archive.Abort()
+	fmt.Fprintf(humanOutput, "Executing %s backup...\n", eng.Name())
+	result, err := eng.Backup(ctx, engine.BackupOptions{
+		URI:     uri,
+		Archive: archive,
+		Output:  humanOutput,
+	})
+	if ctxErr := interrupted(ctx, "backup"); ctxErr != nil {
+		archive.Abort()
+		fmt.Fprintln(humanOutput, "The partial archive was removed.")
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, withCode(codeToolFailed, fmt.Errorf("backup failed: %w", err))
+	}
+	if err := archive.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to save '%s': %w", backupFilePath, err)
+	}
+	finishedAt := time.Now()
+
+	manifest, err := writeBackupManifest(eng, uri, backupFilePath, startedAt, finishedAt, result)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintln(humanOutput, "------------------------")
+	fmt.Fprintln(humanOutput, "Backup completed successfully!")
+
+	applyRetention(backupDir, eng.Extension())
+	return &backupResult{
+		Engine:          manifest.Engine,
+		Archive:         backupFilePath,
+		Size:            manifest.Size,
+		SHA256:          manifest.SHA256,
+		Encrypted:       manifest.Encrypted,
+		SourceHost:      manifest.SourceHost,
+		Namespaces:      manifest.Namespaces,
+		StartedAt:       startedAt,
+		FinishedAt:      finishedAt,
+		DurationSeconds: finishedAt.Sub(startedAt).Round(time.Millisecond).Seconds(),
+	}, nil
+}
+
+// writeBackupManifest records checksum, tool versions and namespaces of a finished archive.
+func writeBackupManifest(eng engine.Engine, uri, backupFilePath string, startedAt, finishedAt time.Time, result *engine.BackupResult) (*backup.Manifest, error) {
+	// ثبت مشخصات بکاپ (checksum، نسخه ابزار، namespaceها و ...) در کنار فایل بکاپ
+	fmt.Fprintln(humanOutput, "Computing archive checksum...")
+	checksum, size, err := backup.Checksum(backupFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute checksum of '%s': %w", backupFilePath, err)
+	}
+	if len(result.Namespaces) == 0 {
+		// موتورهایی که فهرست را هنگام بکاپ نمی‌دانند، از خود آرشیو خوانده می‌شوند
+		result.Namespaces = archiveContents(eng, backupFilePath)
+	}
+	manifest := &backup.Manifest{
+		Archive:            filepath.Base(backupFilePath),
+		Engine:             eng.Name(),
+		SHA256:             checksum,
+		Size:               size,
+		SourceHost:         engine.SourceHost(uri),
+		ToolVersion:        result.ToolVersion,
+		CLIVersion:         Version,
+		StartedAt:          startedAt,
+		FinishedAt:         finishedAt,
+		Namespaces:         result.Namespaces,
+		ExcludedNamespaces: result.ExcludedNamespaces,
+		Encrypted:          strings.HasSuffix(backupFilePath, backup.EncryptedSuffix),
+	}
+	// آرشیوهای دارای oplog، بازه آن را برای بازیابی تا یک لحظه مشخص ثبت می‌کنند
+	if err := recordOplogRange(eng, backupFilePath, startedAt, manifest); err != nil {
+		return nil, err
+	}
+	if err := backup.WriteManifest(backupFilePath, manifest); err != nil {
+		return nil, fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	fmt.Fprintf(humanOutput, "Manifest saved to: %s\n", backup.ManifestPath(backupFilePath))
+	fmt.Fprintf(humanOutput, "Archive size: %s, SHA-256: %s\n", backup.FormatSize(size), checksum)
+	return manifest, nil
+}
+
+// encryptionPassphrase returns the archive encryption passphrase of the active profile
+// ('encryption.passphrase', usually a secret reference), or "" if archives are not encrypted.
+func encryptionPassphrase() (string, error) {
+	passphrase, err := config.GetSecret("encryption.passphrase")
+	if err != nil {
+		return "", withCode(codeConfig, fmt.Errorf("configuration error: %w", err))
+	}
+	return passphrase, nil
+}
+
+// archiveExtension returns the file extension of new archives of eng.
+func archiveExtension(eng engine.Engine, encrypted bool) string {
+	if encrypted {
+		return eng.Extension() + backup.EncryptedSuffix
+	}
+	return eng.Extension()
+}
+
+// openArchive opens an archive for reading, decrypting it with the passphrase of the
+// active profile if it is encrypted.
+func openArchive(path string) (io.ReadCloser, error) {
+	return backup.Open(path, func() (string, error) {
+		return config.GetSecret("encryption.passphrase")
+	})
+}
+
+// archiveContents returns the namespaces stored in an archive, or nil if they cannot be read.
+func archiveContents(eng engine.Engine, path string) []string {
+	archive, err := openArchive(path)
+	if err != nil {
+		return nil
+	}
+	defer archive.Close()
+	contents, err := eng.ListContents(context.Background(), archive)
+	if err != nil {
+		return nil
+	}
+	return contents
+}
+
+// applyRetention prunes the archives with the given extension after a successful backup.
+func applyRetention(backupDir, ext string) {
+	policy, err := retentionPolicyFromConfig()
+	if err != nil {
+		log.Printf("Warning: skipping retention: %v", err)
+	} else if !policy.IsZero() {
+		mongoExt := (&mongodb.Engine{}).Extension()
+		if ext == mongodb.OplogExtension {
+			// آرشیوهای oplog به snapshotها وابسته‌اند و همراه آن‌ها چرخش داده می‌شوند
+			ext = mongoExt
+		}
+		if _, err := pruneBackups(backupDir, ext, policy, false); err != nil {
+			log.Printf("Warning: retention failed: %v", err)
+		}
+		if ext == mongoExt {
+			if _, err := pruneOplogSlices(backupDir, false); err != nil {
+				log.Printf("Warning: oplog retention failed: %v", err)
+			}
+		}
+	}
+}
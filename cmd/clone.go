@@ -0,0 +1,21 @@
+// فایل: cmd/clone.go
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var cloneCmd = &cobra.Command{
+	Use:     "clone",
+	Aliases: []string{"sync"},
+	Short:   "Copy a remote database directly into the local one",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprintln(humanOutput, "Please specify a subcommand, e.g., 'mongo'.")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cloneCmd)
+}
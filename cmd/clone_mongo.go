@@ -0,0 +1,184 @@
+// فایل: cmd/clone_mongo.go
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/backup"
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+	"github.com/mshamsi502/dataweaver-cli/internal/mongodb"
+	"github.com/mshamsi502/dataweaver-cli/internal/redact"
+
+	"github.com/spf13/cobra"
+)
+
+// cloneMongoCmd represents the mongo subcommand of clone
+var cloneMongoCmd = &cobra.Command{
+	Use:   "mongo",
+	Short: "Clone the remote MongoDB database into the local one",
+	Long: `Pipes the output of mongodump (remote URI) straight into mongorestore (local URI),
+without writing an intermediate file. This is the quickest way to refresh a local
+database from a remote one.
+
+Namespaces can be renamed on the way with pairs of --ns-from/--ns-to, e.g.
+  --ns-from 'app.*' --ns-to 'app_copy.*'
+Use --keep to also save the archive (with its manifest) in the backup directory.
+The same namespace selection flags as 'backup mongo' are supported.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Fprintln(humanOutput, "Starting MongoDB clone...")
+		if profile := config.ActiveProfile(); profile != "" {
+			fmt.Fprintf(humanOutput, "Using profile: %s\n", profile)
+		}
+
+		remoteURI, err := uriFromConfig("mongodb.remote_uri")
+		if err != nil {
+			return err
+		}
+		localURI, err := uriFromConfig("mongodb.local_uri")
+		if err != nil {
+			return err
+		}
+		backupDir := config.GetString("paths.backup")
+
+		if remoteURI == "" || localURI == "" {
+			return errorf(codeConfig, "configuration error: 'mongodb.remote_uri' and 'mongodb.local_uri' must be set. Please run 'dataweaver-cli configure' first")
+		}
+
+		selection := mongoSelectionFromFlags(cmd)
+		if err := selection.Validate(); err != nil {
+			return errorf(codeUsage, "invalid namespace selection: %w", err)
+		}
+		nsFrom, _ := cmd.Flags().GetStringArray("ns-from")
+		nsTo, _ := cmd.Flags().GetStringArray("ns-to")
+		if len(nsFrom) != len(nsTo) {
+			return errorf(codeUsage, "every --ns-from needs a matching --ns-to")
+		}
+		keep, _ := cmd.Flags().GetBool("keep")
+		if keep && backupDir == "" {
+			return errorf(codeConfig, "configuration error: 'paths.backup' must be set to use --keep")
+		}
+
+		fmt.Fprintf(humanOutput, "Source: %s\n", redact.String(remoteURI))
+		fmt.Fprintf(humanOutput, "Target: %s\n", redact.String(localURI))
+		fmt.Fprintf(humanOutput, "Namespaces: %s\n", strings.Join(selection.Namespaces(), ", "))
+		for i := range nsFrom {
+			fmt.Fprintf(humanOutput, "Renaming: %s -> %s\n", nsFrom[i], nsTo[i])
+		}
+
+		if err := confirmRestore(cmd, fmt.Sprintf("Clone %s into %s? Existing collections will be dropped.", engine.SourceHost(remoteURI), engine.SourceHost(localURI))); err != nil {
+			return err
+		}
+
+		toolsPath, err := findMongoTools()
+		if err != nil {
+			return err
+		}
+		eng := &mongodb.Engine{ToolsPath: toolsPath, Selection: selection}
+		ctx, cancel := operationContext(cmd)
+		defer cancel()
+
+		// در صورت استفاده از --keep، یک کپی از آرشیو در پوشه بکاپ ذخیره می‌شود
+		var copyFile *backup.File
+		var copyPath string
+		startedAt := time.Now()
+		if keep {
+			if err := os.MkdirAll(backupDir, 0755); err != nil {
+				return fmt.Errorf("failed to create backup directory '%s': %w", backupDir, err)
+			}
+			passphrase, err := encryptionPassphrase()
+			if err != nil {
+				return err
+			}
+			unlock, err := backup.Lock(backupDir)
+			if err != nil {
+				return withCode(codeBusy, err)
+			}
+			defer unlock()
+			copyPath = filepath.Join(backupDir, backup.ArchiveName(startedAt, archiveExtension(eng, passphrase != "")))
+			f, err := backup.Create(copyPath, passphrase)
+			if err != nil {
+				return fmt.Errorf("failed to create '%s': %w", copyPath, err)
+			}
+			defer f.Abort()
+			copyFile = f
+			fmt.Fprintf(humanOutput, "A copy of the archive will be saved to: %s\n", copyPath)
+		}
+
+		fmt.Fprintln(humanOutput, "Executing mongodump | mongorestore. This might take a while...")
+		opts := mongodb.CloneOptions{
+			SourceURI: remoteURI,
+			TargetURI: localURI,
+			NSFrom:    nsFrom,
+			NSTo:      nsTo,
+			Drop:      true,
+			Output:    humanOutput,
+		}
+		if copyFile != nil {
+			opts.Copy = copyFile
+		}
+		result, err := eng.Clone(ctx, opts)
+		// در صورت خطا، نسخه ناقص آرشیو توسط Abort حذف می‌شود
+		if ctxErr := interrupted(ctx, "clone"); ctxErr != nil {
+			return fmt.Errorf("%w; the target database may be partially restored", ctxErr)
+		}
+		if err != nil {
+			return errorf(codeToolFailed, "clone failed: %w", err)
+		}
+		if copyFile != nil {
+			if err := copyFile.Commit(); err != nil {
+				return fmt.Errorf("failed to save '%s': %w", copyPath, err)
+			}
+		}
+
+		finishedAt := time.Now()
+		cloned := &cloneResult{
+			Source:          engine.SourceHost(remoteURI),
+			Target:          engine.SourceHost(localURI),
+			Namespaces:      selection.Namespaces(),
+			DurationSeconds: finishedAt.Sub(startedAt).Round(time.Millisecond).Seconds(),
+		}
+		for i := range nsFrom {
+			cloned.Renames = append(cloned.Renames, nsFrom[i]+" -> "+nsTo[i])
+		}
+		if copyFile != nil {
+			manifest, err := writeBackupManifest(eng, remoteURI, copyPath, startedAt, finishedAt, result)
+			if err != nil {
+				return err
+			}
+			cloned.Archive, cloned.SHA256 = copyPath, manifest.SHA256
+			applyRetention(backupDir, eng.Extension())
+		}
+
+		fmt.Fprintln(humanOutput, "------------------------")
+		fmt.Fprintln(humanOutput, "MongoDB clone completed successfully!")
+		setResult(cloned)
+		return nil
+	},
+}
+
+// cloneResult is the outcome of 'clone mongo', reported by '--output json'.
+type cloneResult struct {
+	Source          string   `json:"source"`
+	Target          string   `json:"target"`
+	Namespaces      []string `json:"namespaces"`
+	Renames         []string `json:"renames,omitempty"`
+	Archive         string   `json:"archive,omitempty"`
+	SHA256          string   `json:"sha256,omitempty"`
+	DurationSeconds float64  `json:"duration_seconds"`
+}
+
+func init() {
+	cloneCmd.AddCommand(cloneMongoCmd)
+
+	addMongoSelectionFlags(cloneMongoCmd)
+	cloneMongoCmd.Flags().StringArray("ns-from", nil, "Source namespace pattern to rename (repeatable, paired with --ns-to)")
+	cloneMongoCmd.Flags().StringArray("ns-to", nil, "Target namespace pattern (repeatable, paired with --ns-from)")
+	cloneMongoCmd.Flags().Bool("keep", false, "Also save the archive in the backup directory")
+	cloneMongoCmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	addTimeoutFlag(cloneMongoCmd)
+}
@@ -9,6 +9,9 @@ import (
 	"path/filepath"
 	"runtime"
 
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/redact"
+
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -28,11 +31,14 @@ var configureCmd = &cobra.Command{
 	Short: "Configure DataWeaver CLI settings.",
 	Long: `This command allows you to configure various settings for the DataWeaver CLI,
 such as database connection details, backup paths, etc.
-Run without flags for an interactive setup.`,
-	Run: func(cmd *cobra.Command, args []string) {
+Run without flags for an interactive setup.
+
+With --profile, the settings are stored in that profile instead of globally.
+See 'configure profile' to manage profiles.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// این تابع مقادیر را از فلگ‌ها یا به صورت تعاملی می‌گیرد و ذخیره می‌کند
 		// (منطق این تابع را برای خوانایی خلاصه می‌کنیم، چون قبلاً پیاده‌سازی شده)
-		runConfiguration(cmd)
+		return runConfiguration(cmd)
 	},
 }
 
@@ -40,8 +46,9 @@ Run without flags for an interactive setup.`,
 var configurePathCmd = &cobra.Command{
 	Use:   "path",
 	Short: "Show the path to the configuration file",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		showConfigPath()
+		return nil
 	},
 }
 
@@ -49,53 +56,88 @@ var configurePathCmd = &cobra.Command{
 var configureEditCmd = &cobra.Command{
 	Use:   "edit",
 	Short: "Open the configuration file in the default editor",
-	Run: func(cmd *cobra.Command, args []string) {
-		editConfigFile()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editConfigFile()
 	},
 }
 
 // توابع کمکی که منطق اصلی را انجام می‌دهند
-func runConfiguration(cmd *cobra.Command) {
-	fmt.Println("Starting interactive configuration...")
+func runConfiguration(cmd *cobra.Command) error {
+	fmt.Fprintln(humanOutput, "Starting interactive configuration...")
+	if profile := config.ActiveProfile(); profile != "" {
+		fmt.Fprintf(humanOutput, "Settings will be stored in profile '%s'.\n", profile)
+	}
 	// ... (منطق کامل پرسیدن سوالات با survey و گرفتن مقادیر از فلگ‌ها که قبلاً نوشتیم) ...
 
 	// گرفتن مقادیر از viper به عنوان پیش‌فرض
-	survey.AskOne(&survey.Input{Message: "Enter MongoDB Remote Server URI:", Default: viper.GetString("mongodb.remote_uri")}, &mongoRemoteURI, survey.WithValidator(survey.Required))
-	survey.AskOne(&survey.Input{Message: "Enter MongoDB Local Server URI:", Default: viper.GetString("mongodb.local_uri")}, &mongoLocalURI, survey.WithValidator(survey.Required))
-	survey.AskOne(&survey.Input{Message: "Enter path to store backups:", Default: viper.GetString("paths.backup")}, &backupPath, survey.WithValidator(survey.Required))
-	survey.AskOne(&survey.Input{Message: "Enter path to MongoDB Database Tools 'bin' directory:", Default: viper.GetString("paths.mongo_tools")}, &mongoToolsPath)
+	mongoRemoteURI = askURI("Enter MongoDB Remote Server URI:", config.GetString("mongodb.remote_uri"), true)
+	mongoLocalURI = askURI("Enter MongoDB Local Server URI:", config.GetString("mongodb.local_uri"), true)
+	askOne(&survey.Input{Message: "Enter path to store backups:", Default: config.GetString("paths.backup")}, &backupPath, survey.WithValidator(survey.Required))
+	askOne(&survey.Input{Message: "Enter path to MongoDB Database Tools 'bin' directory (empty to auto-detect):", Default: config.GetString("paths.mongo_tools")}, &mongoToolsPath)
+
+	remoteURI, err := storePasswordSeparately("mongodb.remote_uri", mongoRemoteURI)
+	if err != nil {
+		return err
+	}
+	localURI, err := storePasswordSeparately("mongodb.local_uri", mongoLocalURI)
+	if err != nil {
+		return err
+	}
+	config.Set("mongodb.remote_uri", remoteURI)
+	config.Set("mongodb.local_uri", localURI)
+	config.Set("paths.backup", backupPath)
+	config.Set("paths.mongo_tools", mongoToolsPath)
 
-	viper.Set("mongodb.remote_uri", mongoRemoteURI)
-	viper.Set("mongodb.local_uri", mongoLocalURI)
-	viper.Set("paths.backup", backupPath)
-	viper.Set("paths.mongo_tools", mongoToolsPath)
+	return saveConfiguration()
+}
 
-	saveConfiguration()
+// askURI asks for a connection string. A current value with credentials is not offered
+// as the editable default, so the password is not shown; it is kept on an empty answer.
+func askURI(message, current string, required bool) string {
+	var answer string
+	if redact.String(current) == current {
+		var opts []survey.AskOpt
+		if required {
+			opts = append(opts, survey.WithValidator(survey.Required))
+		}
+		askOne(&survey.Input{Message: message, Default: current}, &answer, opts...)
+		return answer
+	}
+	askOne(&survey.Input{Message: fmt.Sprintf("%s (current: %s, press Enter to keep)", message, redact.String(current))}, &answer)
+	if answer == "" {
+		return current
+	}
+	return answer
 }
 
 func showConfigPath() {
 	setupViperConfigPaths()
 	configFile := viper.ConfigFileUsed()
 	if configFile != "" {
-		fmt.Printf("Configuration file is located at:\n%s\n", configFile)
+		fmt.Fprintf(humanOutput, "Configuration file is located at:\n%s\n", configFile)
+		setResult(map[string]any{"config_file": configFile, "exists": true})
 	} else {
 		defaultPath := getConfigFilePath()
-		fmt.Printf("Configuration file not yet created. It will be created at:\n%s\n", defaultPath)
+		fmt.Fprintf(humanOutput, "Configuration file not yet created. It will be created at:\n%s\n", defaultPath)
+		setResult(map[string]any{"config_file": defaultPath, "exists": false})
 	}
 }
 
-func editConfigFile() {
+func editConfigFile() error {
 	setupViperConfigPaths()
 	configFile := viper.ConfigFileUsed()
 	if configFile == "" {
 		configFile = getConfigFilePath()
 		if _, err := os.Stat(configFile); os.IsNotExist(err) {
 			log.Printf("Configuration file not found. Creating a new one at: %s\n", configFile)
-			saveConfiguration()
+			if err := saveConfiguration(); err != nil {
+				return err
+			}
 		}
 	}
 
-	fmt.Printf("Opening config file: %s\n", configFile)
+	fmt.Fprintf(humanOutput, "Opening config file: %s\n", configFile)
+	setResult(map[string]string{"config_file": configFile})
 
 	var editorCmd *exec.Cmd
 	switch runtime.GOOS {
@@ -106,27 +148,43 @@ func editConfigFile() {
 	case "linux":
 		editorCmd = exec.Command("xdg-open", configFile)
 	default:
-		fmt.Printf("Unsupported OS: %s. Please open the file manually at:\n%s\n", runtime.GOOS, configFile)
-		return
+		fmt.Fprintf(humanOutput, "Unsupported OS: %s. Please open the file manually at:\n%s\n", runtime.GOOS, configFile)
+		return nil
 	}
 
 	err := editorCmd.Run()
 	if err != nil {
 		log.Printf("Failed to open editor: %v. Please open the file manually at:\n%s\n", err, configFile)
 	}
+	return nil
 }
 
-func saveConfiguration() {
+func saveConfiguration() error {
 	configFile := getConfigFilePath()
 	configDir := filepath.Dir(configFile)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		log.Fatalf("Error creating config directory: %v", err)
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
 	}
 	if err := viper.WriteConfigAs(configFile); err != nil {
-		log.Fatalf("Error writing config file: %v", err)
+		return fmt.Errorf("error writing config file: %w", err)
 	}
+	restrictConfigPermissions(configFile)
 	viper.SetConfigFile(configFile)
-	fmt.Printf("Configuration saved successfully to: %s\n", configFile)
+	fmt.Fprintf(humanOutput, "Configuration saved successfully to: %s\n", configFile)
+	setResult(map[string]string{"config_file": configFile})
+	return nil
+}
+
+// restrictConfigPermissions makes the config file, and the ~/.dataweaver-cli directory
+// holding it, accessible only by the owner, since it may contain credentials.
+func restrictConfigPermissions(configFile string) {
+	if err := os.Chmod(configFile, 0600); err != nil {
+		log.Printf("Warning: could not restrict permissions of '%s': %v", configFile, err)
+	}
+	// پوشه فعلی (در حالت config.yaml محلی) را تغییر نمی‌دهیم
+	if configDir := filepath.Dir(configFile); filepath.Base(configDir) == ".dataweaver-cli" {
+		os.Chmod(configDir, 0700)
+	}
 }
 
 func getConfigFilePath() string {
@@ -0,0 +1,190 @@
+// فایل: cmd/configure_profile.go
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// --- دستور 'configure profile' و زیردستورات آن ---
+var configureProfileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named connection profiles",
+	Long: `Profiles let you keep several connection setups (e.g. prod, staging, dev) in one
+config file. Every setting inside 'profiles.<name>' overrides the global value of
+the same key, so a profile only needs the settings that differ:
+
+  default_profile: staging
+  profiles:
+    prod:
+      mongodb:
+        remote_uri: mongodb://backup@prod-db:27017
+      paths:
+        backup: ./backups/prod
+    staging:
+      mongodb:
+        remote_uri: mongodb://backup@staging-db:27017
+
+Select a profile for a single command with the global --profile flag.
+Profile names are case-insensitive.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listProfiles()
+		return nil
+	},
+}
+
+var configureProfileAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a new profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if config.ProfileExists(name) {
+			return errorf(codeUsage, "profile '%s' already exists. Use 'configure --profile %s' to change it", name, name)
+		}
+		config.SetActiveProfile(name)
+
+		remoteURI, _ := cmd.Flags().GetString("mongo-remote-uri")
+		localURI, _ := cmd.Flags().GetString("mongo-local-uri")
+		backupDir, _ := cmd.Flags().GetString("backup-path")
+
+		// مقادیری که با فلگ داده نشده‌اند به صورت تعاملی پرسیده می‌شوند
+		if !cmd.Flags().Changed("mongo-remote-uri") {
+			askOne(&survey.Input{Message: "Enter MongoDB Remote Server URI:"}, &remoteURI, survey.WithValidator(survey.Required))
+		}
+		if !cmd.Flags().Changed("mongo-local-uri") {
+			localURI = askURI("Enter MongoDB Local Server URI:", config.GetString("mongodb.local_uri"), false)
+		}
+		if !cmd.Flags().Changed("backup-path") {
+			askOne(&survey.Input{Message: "Enter path to store backups for this profile:", Default: config.GetString("paths.backup")}, &backupDir)
+		}
+		if remoteURI == "" {
+			return errorf(codeUsage, "a remote URI is required to create a profile")
+		}
+
+		remoteURI, err := storePasswordSeparately("mongodb.remote_uri", remoteURI)
+		if err != nil {
+			return err
+		}
+		viper.Set(config.ProfileKey(name, "mongodb.remote_uri"), remoteURI)
+		if localURI != "" {
+			localURI, err := storePasswordSeparately("mongodb.local_uri", localURI)
+			if err != nil {
+				return err
+			}
+			viper.Set(config.ProfileKey(name, "mongodb.local_uri"), localURI)
+		}
+		if backupDir != "" {
+			viper.Set(config.ProfileKey(name, "paths.backup"), backupDir)
+		}
+		if makeDefault, _ := cmd.Flags().GetBool("default"); makeDefault || viper.GetString("default_profile") == "" {
+			viper.Set("default_profile", name)
+		}
+
+		if err := saveConfiguration(); err != nil {
+			return err
+		}
+		fmt.Fprintf(humanOutput, "Profile '%s' added.\n", name)
+		setResult(map[string]string{"profile": name, "config_file": viper.ConfigFileUsed()})
+		return nil
+	},
+}
+
+var configureProfileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configured profiles",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listProfiles()
+		return nil
+	},
+}
+
+var configureProfileRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.RemoveProfile(args[0]); err != nil {
+			return withCode(codeUsage, err)
+		}
+		if err := saveConfiguration(); err != nil {
+			return err
+		}
+		fmt.Fprintf(humanOutput, "Profile '%s' removed.\n", args[0])
+		setResult(map[string]string{"profile": args[0], "config_file": viper.ConfigFileUsed()})
+		return nil
+	},
+}
+
+var configureProfileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Set the default profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !config.ProfileExists(args[0]) {
+			return errorf(codeUsage, "profile '%s' is not configured. Available profiles: %v", args[0], config.ProfileNames())
+		}
+		viper.Set("default_profile", args[0])
+		if err := saveConfiguration(); err != nil {
+			return err
+		}
+		fmt.Fprintf(humanOutput, "Default profile set to '%s'.\n", args[0])
+		setResult(map[string]string{"default_profile": args[0], "config_file": viper.ConfigFileUsed()})
+		return nil
+	},
+}
+
+// profileInfo describes a profile in the JSON output of 'configure profile list'.
+type profileInfo struct {
+	Name      string `json:"name"`
+	Default   bool   `json:"default"`
+	Remote    string `json:"remote"`
+	BackupDir string `json:"backup_dir"`
+}
+
+// listProfiles prints the configured profiles, marking the default one.
+func listProfiles() {
+	names := config.ProfileNames()
+	profiles := make([]profileInfo, 0, len(names))
+	defer func() { setResult(profiles) }()
+	if len(names) == 0 {
+		fmt.Fprintln(humanOutput, "No profiles configured. Add one with 'dataweaver-cli configure profile add <name>'.")
+		return
+	}
+	defaultProfile := viper.GetString("default_profile")
+	for _, name := range names {
+		marker := " "
+		if name == defaultProfile {
+			marker = "*"
+		}
+		remote := viper.GetString(config.ProfileKey(name, "mongodb.remote_uri"))
+		if remote == "" {
+			remote = viper.GetString("mongodb.remote_uri")
+		}
+		backupDir := viper.GetString(config.ProfileKey(name, "paths.backup"))
+		if backupDir == "" {
+			backupDir = viper.GetString("paths.backup")
+		}
+		fmt.Fprintf(humanOutput, "%s %-15s remote: %-30s backups: %s\n", marker, name, engine.SourceHost(remote), backupDir)
+		profiles = append(profiles, profileInfo{Name: name, Default: name == defaultProfile, Remote: engine.SourceHost(remote), BackupDir: backupDir})
+	}
+}
+
+func init() {
+	configureCmd.AddCommand(configureProfileCmd)
+	configureProfileCmd.AddCommand(configureProfileAddCmd)
+	configureProfileCmd.AddCommand(configureProfileListCmd)
+	configureProfileCmd.AddCommand(configureProfileRemoveCmd)
+	configureProfileCmd.AddCommand(configureProfileUseCmd)
+
+	configureProfileAddCmd.Flags().StringP("mongo-remote-uri", "r", "", "MongoDB remote server URI")
+	configureProfileAddCmd.Flags().StringP("mongo-local-uri", "l", "", "MongoDB local server URI (default: the global value)")
+	configureProfileAddCmd.Flags().StringP("backup-path", "b", "", "Path to store backups (default: the global value)")
+	configureProfileAddCmd.Flags().Bool("default", false, "Make this the default profile")
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+var configureSecretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage secrets stored in the encrypted secrets file",
+	Long: `Secrets are kept in a passphrase protected file (~/.dataweaver-cli/secrets.enc by
+default, or 'secrets.file') instead of in config.yaml. Refer to them from any URI or
+password setting as 'secret:<name>'. Settings can also refer to 'env:<VARIABLE>' or
+'file:<path>'.
+
+The passphrase is asked for once per run, or read from DATAWEAVER_SECRETS_PASSPHRASE.`,
+}
+
+var configureSecretSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Add or replace a secret (the value is read from the terminal or stdin)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var value string
+		if isInteractive() {
+			askOne(&survey.Password{Message: fmt.Sprintf("Value of '%s':", args[0])}, &value, survey.WithValidator(survey.Required))
+		} else {
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				return fmt.Errorf("error reading the secret from stdin: %w", err)
+			}
+			value = strings.TrimRight(line, "\r\n")
+		}
+
+		store, err := config.OpenSecrets()
+		if err != nil {
+			return withCode(codeConfig, err)
+		}
+		store.Set(args[0], value)
+		if err := store.Save(); err != nil {
+			return fmt.Errorf("error writing the secrets file: %w", err)
+		}
+		fmt.Fprintf(humanOutput, "Secret '%s' saved. Refer to it as 'secret:%s'.\n", args[0], args[0])
+		setResult(map[string]string{"secret": args[0], "reference": "secret:" + args[0]})
+		return nil
+	},
+}
+
+var configureSecretListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the names of the stored secrets",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := config.OpenSecrets()
+		if err != nil {
+			return withCode(codeConfig, err)
+		}
+		names := store.Names()
+		setResult(map[string][]string{"secrets": append([]string{}, names...)})
+		if len(names) == 0 {
+			fmt.Fprintln(humanOutput, "No secrets stored.")
+			return nil
+		}
+		for _, name := range names {
+			fmt.Fprintln(humanOutput, name)
+		}
+		return nil
+	},
+}
+
+var configureSecretRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a secret",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := config.OpenSecrets()
+		if err != nil {
+			return withCode(codeConfig, err)
+		}
+		if !store.Delete(args[0]) {
+			return errorf(codeUsage, "secret '%s' does not exist", args[0])
+		}
+		if err := store.Save(); err != nil {
+			return fmt.Errorf("error writing the secrets file: %w", err)
+		}
+		fmt.Fprintf(humanOutput, "Secret '%s' removed.\n", args[0])
+		setResult(map[string]string{"secret": args[0]})
+		return nil
+	},
+}
+
+// uriFromConfig returns a connection string setting of the active profile with its
+// secret references resolved.
+func uriFromConfig(key string) (string, error) {
+	uri, err := config.GetURI(key)
+	if err != nil {
+		return "", errorf(codeConfig, "configuration error: %w", err)
+	}
+	return uri, nil
+}
+
+// askSecretsPassphrase asks for the passphrase of the secrets file, twice if the file
+// is about to be created.
+func askSecretsPassphrase() (string, error) {
+	if !isInteractive() {
+		return "", fmt.Errorf("the secrets file is locked; set %s", config.SecretsPassphraseEnv)
+	}
+	var passphrase string
+	if _, err := os.Stat(config.SecretsFilePath()); os.IsNotExist(err) {
+		fmt.Fprintf(humanOutput, "Creating the secrets file %s.\n", config.SecretsFilePath())
+		var repeated string
+		askOne(&survey.Password{Message: "Choose a passphrase for the secrets file:"}, &passphrase, survey.WithValidator(survey.Required))
+		askOne(&survey.Password{Message: "Repeat the passphrase:"}, &repeated)
+		if passphrase != repeated {
+			return "", errors.New("the passphrases do not match")
+		}
+		return passphrase, nil
+	}
+	askOne(&survey.Password{Message: "Passphrase for the secrets file:"}, &passphrase)
+	return passphrase, nil
+}
+
+// storePasswordSeparately offers to move the password out of a connection string
+// before it is saved, into the secrets file, an environment variable or a file.
+// It returns the URI to store under uriKey.
+func storePasswordSeparately(uriKey, uri string) (string, error) {
+	stripped, password := config.SplitPassword(uri)
+	if password == "" || !isInteractive() {
+		return uri, nil
+	}
+
+	const (
+		inSecrets = "Encrypted secrets file (passphrase protected)"
+		inEnv     = "Environment variable"
+		inFile    = "Separate file (readable only by you)"
+		inConfig  = "Keep it in config.yaml (plain text)"
+	)
+	var choice string
+	askOne(&survey.Select{
+		Message: fmt.Sprintf("Where should the password of '%s' be stored?", uriKey),
+		Options: []string{inSecrets, inEnv, inFile, inConfig},
+		Default: inSecrets,
+	}, &choice)
+
+	passwordKey := config.PasswordKey(uriKey)
+	// نام یکتا برای هر پروفایل، مثلاً "profiles.prod.mongodb.remote_password"
+	name := passwordKey
+	if profile := config.ActiveProfile(); profile != "" {
+		name = config.ProfileKey(profile, passwordKey)
+	}
+
+	switch choice {
+	case inSecrets:
+		store, err := config.OpenSecrets()
+		if err != nil {
+			return "", withCode(codeConfig, err)
+		}
+		store.Set(name, password)
+		if err := store.Save(); err != nil {
+			return "", fmt.Errorf("error writing the secrets file: %w", err)
+		}
+		config.Set(passwordKey, "secret:"+name)
+	case inEnv:
+		envName := "DATAWEAVER_" + strings.ToUpper(strings.ReplaceAll(name, ".", "_"))
+		askOne(&survey.Input{Message: "Environment variable name:", Default: envName}, &envName)
+		config.Set(passwordKey, "env:"+envName)
+		fmt.Fprintf(humanOutput, "Remember to set %s before running backups or restores.\n", envName)
+	case inFile:
+		path := filepath.Join(filepath.Dir(getConfigFilePath()), name+".secret")
+		askOne(&survey.Input{Message: "Password file:", Default: path}, &path)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return "", fmt.Errorf("error creating %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(password+"\n"), 0600); err != nil {
+			return "", fmt.Errorf("error writing %s: %w", path, err)
+		}
+		config.Set(passwordKey, "file:"+path)
+	default:
+		config.Set(passwordKey, "")
+		return uri, nil
+	}
+	return stripped, nil
+}
+
+func init() {
+	config.PassphraseFunc = askSecretsPassphrase
+
+	configureCmd.AddCommand(configureSecretCmd)
+	configureSecretCmd.AddCommand(configureSecretSetCmd)
+	configureSecretCmd.AddCommand(configureSecretListCmd)
+	configureSecretCmd.AddCommand(configureSecretRemoveCmd)
+}
@@ -0,0 +1,46 @@
+// فایل: cmd/context.go
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// operationContext returns the context a backup, restore or clone runs under. It is
+// cancelled by Ctrl+C or SIGTERM and, if --timeout is set on cmd, when the timeout
+// expires. The external tools are stopped when it is done.
+func operationContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// interrupted returns the error to report when ctx stopped the operation what, or nil
+// if ctx is still running.
+func interrupted(ctx context.Context, what string) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return withCode(codeTimeout, fmt.Errorf("%s timed out (--timeout)", what))
+	case ctx.Err() != nil:
+		return withCode(codeCancelled, fmt.Errorf("%s cancelled", what))
+	}
+	return nil
+}
+
+// addTimeoutFlag registers --timeout on a command that runs external tools.
+func addTimeoutFlag(cmd *cobra.Command) {
+	cmd.Flags().Duration("timeout", 0, "Abort if the operation takes longer than this, e.g. 2h or 90m (default: no limit)")
+}
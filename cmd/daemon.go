@@ -0,0 +1,254 @@
+// فایل: cmd/daemon.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/backup"
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/redact"
+	"github.com/mshamsi502/dataweaver-cli/internal/schedule"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// scheduledBackups maps the engine names used in 'schedule' to the backup they run
+// and the command whose flags (all left at their defaults) it reads.
+var scheduledBackups = map[string]struct {
+	cmd *cobra.Command
+	run func(context.Context, *cobra.Command) (*backupResult, error)
+}{
+	"mongo":       {backupMongoCmd, backupMongo},
+	"mongo_oplog": {backupMongoCmd, backupMongoOplog},
+	"postgres":    {backupPostgresCmd, backupPostgres},
+	"mysql":       {backupMySQLCmd, backupMySQL},
+}
+
+// daemonJob is one scheduled backup of an engine in a profile.
+type daemonJob struct {
+	profile  string // empty: the default profile
+	engine   string
+	schedule *schedule.Schedule
+	next     time.Time
+}
+
+func (j *daemonJob) String() string {
+	if j.profile == "" {
+		return j.engine
+	}
+	return j.profile + "/" + j.engine
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run scheduled backups in a long-running process",
+	Long: `Runs the backups scheduled in the config file until it is stopped with Ctrl+C or
+SIGTERM. Schedules are cron expressions (minute hour day-of-month month day-of-week,
+in local time) per engine, defined globally for the default profile or per profile:
+
+  schedule:
+    mongo: "0 2 * * *"          # every night at 02:00
+  profiles:
+    prod:
+      schedule:
+        mongo: "*/30 * * * *"   # every 30 minutes
+        postgres: "@daily"
+
+'mongo_oplog' schedules incremental oplog backups ('backup mongo --incremental').
+
+Backups run one at a time with the same settings as 'backup <engine>', including the
+retention policy and 'storage.upload'. A backup due while another one is still running
+starts when it finishes; runs missed because a backup of the same job took longer
+than its interval are skipped. The backup directory is locked while a backup runs, so
+a manual backup started at the same time fails instead of overlapping.
+
+Ctrl+C or SIGTERM also cancels a backup that is running; its partial archive is
+removed. --timeout limits the duration of each backup.
+
+Every run is logged with its duration, archive and result to stderr and to --log-file
+(or 'daemon.log_file').`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jobs, err := loadDaemonJobs()
+		if err != nil {
+			return errorf(codeConfig, "configuration error: %w", err)
+		}
+		if len(jobs) == 0 {
+			return errorf(codeConfig, "no backups are scheduled. Add a 'schedule' section to the config file (see 'dataweaver-cli daemon --help')")
+		}
+
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			var scheduled []map[string]any
+			for _, job := range jobs {
+				fmt.Fprintf(humanOutput, "%-24s %-20s next run: %s\n", job, job.schedule, job.next.Format("2006-01-02 15:04"))
+				scheduled = append(scheduled, map[string]any{"job": job.String(), "profile": job.profile, "engine": job.engine, "schedule": job.schedule.String(), "next_run": job.next})
+			}
+			setResult(scheduled)
+			return nil
+		}
+
+		logFile := viper.GetString("daemon.log_file")
+		if cmd.Flags().Changed("log-file") {
+			logFile, _ = cmd.Flags().GetString("log-file")
+		}
+		if logFile != "" {
+			f, err := os.OpenFile(config.ExpandHome(logFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to open log file: %w", err)
+			}
+			defer f.Close()
+			log.SetOutput(redact.NewWriter(io.MultiWriter(os.Stderr, f)))
+		}
+
+		// توقف با Ctrl+C یا SIGTERM؛ بکاپ در حال اجرا هم لغو می‌شود
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		runDaemon(ctx, jobs, timeout)
+		return nil
+	},
+}
+
+// loadDaemonJobs reads the global and per profile 'schedule' sections.
+func loadDaemonJobs() ([]*daemonJob, error) {
+	type source struct{ profile, key string }
+	sources := []source{{"", "schedule"}}
+	for _, name := range config.ProfileNames() {
+		sources = append(sources, source{name, config.ProfileKey(name, "schedule")})
+	}
+
+	now := time.Now()
+	var jobs []*daemonJob
+	for _, src := range sources {
+		// تنظیمات سراسری به پروفایل‌ها به ارث نمی‌رسد، پس کلید هر پروفایل مستقیم خوانده می‌شود
+		schedules := viper.GetStringMapString(src.key)
+		engines := make([]string, 0, len(schedules))
+		for engineName := range schedules {
+			engines = append(engines, engineName)
+		}
+		sort.Strings(engines)
+		for _, engineName := range engines {
+			if _, ok := scheduledBackups[engineName]; !ok {
+				return nil, fmt.Errorf("%s: unknown engine %q (supported: mongo, mongo_oplog, postgres, mysql)", src.key, engineName)
+			}
+			s, err := schedule.Parse(schedules[engineName])
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", src.key, engineName, err)
+			}
+			job := &daemonJob{profile: src.profile, engine: engineName, schedule: s, next: s.Next(now)}
+			if job.next.IsZero() {
+				return nil, fmt.Errorf("%s.%s: %q never fires", src.key, engineName, s)
+			}
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+// runDaemon runs the jobs at their scheduled times, one at a time, until ctx is done.
+// A timeout > 0 limits the duration of each run.
+func runDaemon(ctx context.Context, jobs []*daemonJob, timeout time.Duration) {
+	log.Printf("Daemon started with %d scheduled backup(s).", len(jobs))
+	for _, job := range jobs {
+		log.Printf("[%s] schedule %q, next run at %s", job, job.schedule, job.next.Format("2006-01-02 15:04"))
+	}
+
+	for {
+		sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].next.Before(jobs[j].next) })
+		timer := time.NewTimer(time.Until(jobs[0].next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Println("Daemon stopped.")
+			return
+		case <-timer.C:
+		}
+
+		for _, job := range jobs {
+			if ctx.Err() != nil {
+				break
+			}
+			if job.next.After(time.Now()) {
+				continue
+			}
+			runDaemonJob(ctx, job, timeout)
+			if ctx.Err() != nil {
+				break
+			}
+
+			next := job.schedule.Next(job.next)
+			if now := time.Now(); next.Before(now) {
+				next = job.schedule.Next(now)
+				log.Printf("[%s] skipped the runs missed while this backup was running", job)
+			}
+			job.next = next
+			log.Printf("[%s] next run at %s", job, job.next.Format("2006-01-02 15:04"))
+		}
+	}
+}
+
+// daemonRun is printed as one JSON line per run with '--output json'.
+type daemonRun struct {
+	Job       string        `json:"job"`
+	Profile   string        `json:"profile,omitempty"`
+	Engine    string        `json:"engine"`
+	Status    string        `json:"status"` // "ok" or "error"
+	StartedAt time.Time     `json:"started_at"`
+	Result    *backupResult `json:"result,omitempty"`
+	Error     *outputError  `json:"error,omitempty"`
+}
+
+// runDaemonJob runs one scheduled backup and logs its result. Failures (and panics)
+// are logged and do not stop the daemon.
+func runDaemonJob(ctx context.Context, job *daemonJob, timeout time.Duration) {
+	startedAt := time.Now()
+	log.Printf("[%s] backup started", job)
+
+	config.SetActiveProfile(job.profile)
+	defer config.SetActiveProfile("")
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	result, err := func() (result *backupResult, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("unexpected error: %v", r)
+			}
+		}()
+		if err := config.ValidateActiveProfile(); err != nil {
+			return nil, withCode(codeConfig, err)
+		}
+		b := scheduledBackups[job.engine]
+		return b.run(ctx, b.cmd)
+	}()
+
+	run := daemonRun{Job: job.String(), Profile: job.profile, Engine: job.engine, StartedAt: startedAt, Result: result}
+	duration := time.Since(startedAt).Round(time.Second)
+	if err != nil {
+		log.Printf("[%s] backup FAILED after %s: %v", job, duration, err)
+		run.Status = "error"
+		run.Error = &outputError{Code: errorCode(err), Message: err.Error()}
+	} else {
+		log.Printf("[%s] backup finished in %s: %s (%s)", job, duration, result.Archive, backup.FormatSize(result.Size))
+		run.Status = "ok"
+	}
+	printJSONLine(run)
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.Flags().String("log-file", "", "Append the run log to this file (default: 'daemon.log_file')")
+	daemonCmd.Flags().Bool("list", false, "Print the scheduled backups and their next run time, then exit")
+	daemonCmd.Flags().Duration("timeout", 0, "Abort a scheduled backup that takes longer than this, e.g. 2h (default: no limit)")
+}
@@ -0,0 +1,402 @@
+// فایل: cmd/mongo_oplog.go
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/backup"
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+	"github.com/mshamsi502/dataweaver-cli/internal/mongodb"
+	"github.com/mshamsi502/dataweaver-cli/internal/redact"
+
+	"github.com/spf13/cobra"
+)
+
+// oplogArchive is a local MongoDB archive whose manifest records the oplog positions it covers.
+type oplogArchive struct {
+	backup.Archive
+	manifest   *backup.Manifest
+	start, end mongodb.Timestamp
+}
+
+// listOplogArchives returns the snapshots taken with --oplog, or the incremental oplog
+// archives if slices is set, in backupDir, oldest first. Archives without a readable
+// manifest are skipped.
+func listOplogArchives(backupDir string, slices bool) ([]oplogArchive, error) {
+	ext := (&mongodb.Engine{}).Extension()
+	if slices {
+		ext = mongodb.OplogExtension
+	}
+	archives, err := backup.List(backupDir, ext)
+	if err != nil {
+		return nil, err
+	}
+	var result []oplogArchive
+	for _, a := range archives {
+		m, err := backup.ReadManifest(a.Path)
+		if err != nil || (slices && !m.OplogSlice) || (!slices && !m.Oplog) {
+			continue
+		}
+		start, err := mongodb.ParseTimestamp(m.OplogStart)
+		if err != nil {
+			continue
+		}
+		end, err := mongodb.ParseTimestamp(m.OplogEnd)
+		if err != nil {
+			continue
+		}
+		result = append(result, oplogArchive{Archive: a, manifest: m, start: start, end: end})
+	}
+	return result, nil
+}
+
+// lastOplogPosition returns the newest oplog position recorded by the snapshots and
+// oplog archives in backupDir, or the zero Timestamp if there is none.
+func lastOplogPosition(backupDir string) (mongodb.Timestamp, error) {
+	var last mongodb.Timestamp
+	for _, slices := range []bool{false, true} {
+		archives, err := listOplogArchives(backupDir, slices)
+		if err != nil {
+			return last, err
+		}
+		for _, a := range archives {
+			if last.Before(a.end) {
+				last = a.end
+			}
+		}
+	}
+	return last, nil
+}
+
+// readOplogRange returns the first and last oplog positions and the number of oplog
+// entries in the archive at path.
+func readOplogRange(path string) (first, last mongodb.Timestamp, n int, err error) {
+	archive, err := openArchive(path)
+	if err != nil {
+		return first, last, 0, err
+	}
+	defer archive.Close()
+	return mongodb.OplogRange(archive)
+}
+
+// recordOplogRange stores the oplog positions covered by a MongoDB snapshot taken
+// with --oplog or by an oplog archive in its manifest m. Other archives are left alone.
+func recordOplogRange(eng engine.Engine, path string, startedAt time.Time, m *backup.Manifest) error {
+	var since *mongodb.Timestamp
+	switch e := eng.(type) {
+	case *mongodb.Engine:
+		if !e.Oplog {
+			return nil
+		}
+	case *mongodb.OplogSlice:
+		since = &e.Since
+	default:
+		return nil
+	}
+	first, last, n, err := readOplogRange(path)
+	if err != nil {
+		return errorf(codeVerification, "failed to read the oplog of '%s': %w", path, err)
+	}
+
+	if since == nil {
+		if n == 0 {
+			// بدون تغییر در حین بکاپ، زمان شروع بکاپ نقطه ادامه است
+			first = mongodb.TimestampAt(startedAt)
+			last = first
+		}
+		fmt.Fprintf(humanOutput, "Oplog: %d entries, consistent at position %s.\n", n, last)
+		m.Oplog, m.OplogStart, m.OplogEnd = true, first.String(), last.String()
+		return nil
+	}
+
+	// ورودی نقطه شروع دوباره خوانده می‌شود؛ نبود آن یعنی oplog سرور چرخیده و تغییراتی از دست رفته است
+	start, end := *since, *since
+	if n > 0 {
+		end = last
+		if first == *since {
+			n--
+		} else if since.I != 0 {
+			start = first
+		}
+	}
+	if n == 0 {
+		fmt.Fprintln(humanOutput, "No new oplog entries.")
+	} else {
+		fmt.Fprintf(humanOutput, "Oplog: %d new entries, up to position %s.\n", n, end)
+	}
+	m.OplogSlice, m.OplogStart, m.OplogEnd = true, start.String(), end.String()
+	return nil
+}
+
+// backupMongoOplog archives the oplog entries written since the last snapshot or oplog
+// archive ('backup mongo --incremental') and returns the result. It is shared by
+// 'backup mongo' and the daemon.
+func backupMongoOplog(ctx context.Context, cmd *cobra.Command) (*backupResult, error) {
+	fmt.Fprintln(humanOutput, "Starting MongoDB oplog backup...")
+	if profile := config.ActiveProfile(); profile != "" {
+		fmt.Fprintf(humanOutput, "Using profile: %s\n", profile)
+	}
+
+	remoteURI, backupDir, err := mongoBackupConfig()
+	if err != nil {
+		return nil, err
+	}
+	since, err := lastOplogPosition(backupDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading backup directory '%s': %w", backupDir, err)
+	}
+	if since.IsZero() {
+		return nil, errorf(codeUsage, "no MongoDB backup with oplog found in '%s'; take a snapshot with 'backup mongo --oplog' first", backupDir)
+	}
+
+	fmt.Fprintf(humanOutput, "Using remote URI: %s\n", redact.String(remoteURI))
+	toolsPath, err := findMongoTools()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(humanOutput, "Backup destination directory: %s\n", backupDir)
+	fmt.Fprintf(humanOutput, "Continuing from oplog position %s (%s).\n", since, since.Time().Format("2006-01-02 15:04:05"))
+
+	result, err := runBackup(ctx, &mongodb.OplogSlice{ToolsPath: toolsPath, Since: since}, remoteURI, backupDir)
+	if err != nil {
+		return nil, err
+	}
+	// آرشیوی که از نقطه شروع ادامه نمی‌دهد، یعنی oplog سرور چرخیده و تغییراتی از دست رفته است
+	m, err := backup.ReadManifest(result.Archive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup manifest: %w", err)
+	}
+	if m.OplogStart != since.String() {
+		return nil, errorf(codeVerification, "the oplog no longer contains position %s: the changes since %s are lost. Take a new snapshot with 'backup mongo --oplog'", since, since.Time().Format("2006-01-02 15:04:05"))
+	}
+
+	result.UploadedTo, err = uploadBackupIfRequested(ctx, cmd, result.Archive)
+	return result, err
+}
+
+// pruneOplogSlices deletes the oplog archives in backupDir that hold nothing newer than
+// the oldest remaining snapshot with oplog; they can no longer be replayed. Without
+// such a snapshot nothing is deleted.
+func pruneOplogSlices(backupDir string, dryRun bool) (*pruneResult, error) {
+	result := &pruneResult{Engine: "mongodb-oplog", DryRun: dryRun, Deleted: []string{}}
+	snapshots, err := listOplogArchives(backupDir, false)
+	if err != nil {
+		return result, fmt.Errorf("error reading backup directory '%s': %w", backupDir, err)
+	}
+	slices, err := listOplogArchives(backupDir, true)
+	if err != nil {
+		return result, fmt.Errorf("error reading backup directory '%s': %w", backupDir, err)
+	}
+	if len(snapshots) == 0 {
+		result.Kept = len(slices)
+		return result, nil
+	}
+
+	oldest := snapshots[0]
+	var freed int64
+	for _, s := range slices {
+		// فایل‌هایی که دستی نام‌گذاری شده‌اند هرگز حذف نمی‌شوند
+		if s.Manual || !s.Time.Before(oldest.Time) || oldest.end.Before(s.end) {
+			result.Kept++
+			continue
+		}
+		if dryRun {
+			fmt.Fprintf(humanOutput, "  would delete: %s (%s)\n", s.Name, backup.FormatSize(s.Size))
+		} else {
+			if err := backup.Remove(s.Archive); err != nil {
+				return result, fmt.Errorf("deleting '%s': %w", s.Path, err)
+			}
+			fmt.Fprintf(humanOutput, "  deleted: %s (%s)\n", s.Name, backup.FormatSize(s.Size))
+		}
+		freed += s.Size
+		result.FreedBytes = freed
+		result.Deleted = append(result.Deleted, s.Name)
+	}
+	if len(result.Deleted) > 0 {
+		if dryRun {
+			fmt.Fprintf(humanOutput, "Oplog retention (dry run): %d archive(s) older than %s would be deleted, %s would be freed.\n", len(result.Deleted), oldest.Name, backup.FormatSize(freed))
+		} else {
+			fmt.Fprintf(humanOutput, "Oplog retention: deleted %d archive(s) older than %s, freed %s.\n", len(result.Deleted), oldest.Name, backup.FormatSize(freed))
+		}
+	}
+	return result, nil
+}
+
+// restoreMongoUntil restores the newest snapshot with oplog that finished before until
+// and replays the oplog archives taken after it up to until ('restore mongo --until').
+func restoreMongoUntil(cmd *cobra.Command, toolsPath, uri, backupDir string, until time.Time) error {
+	ctx, cancel := operationContext(cmd)
+	defer cancel()
+
+	// 1. انتخاب جدیدترین snapshot که پیش از زمان هدف تمام شده است
+	snapshots, err := listOplogArchives(backupDir, false)
+	if err != nil {
+		return fmt.Errorf("error reading backup directory '%s': %w", backupDir, err)
+	}
+	var snapshot *oplogArchive
+	for i := range snapshots {
+		if !snapshots[i].manifest.FinishedAt.After(until) {
+			snapshot = &snapshots[i]
+		}
+	}
+	if snapshot == nil {
+		return errorf(codeUsage, "no MongoDB backup with oplog finished before %s found in '%s'", until.Format(backup.TimestampLayout), backupDir)
+	}
+
+	// 2. انتخاب آرشیوهای oplog پیوسته بعد از snapshot تا زمان هدف
+	slices, err := listOplogArchives(backupDir, true)
+	if err != nil {
+		return fmt.Errorf("error reading backup directory '%s': %w", backupDir, err)
+	}
+	var replay []oplogArchive
+	position, coveredUntil, gap := snapshot.end, snapshot.manifest.FinishedAt, false
+	for _, s := range slices {
+		if !coveredUntil.Before(until) {
+			break
+		}
+		if !coveredUntil.Before(s.manifest.StartedAt) {
+			continue
+		}
+		if position.Before(s.start) {
+			gap = true
+			break
+		}
+		replay = append(replay, s)
+		if position.Before(s.end) {
+			position = s.end
+		}
+		coveredUntil = s.manifest.StartedAt
+	}
+	if coveredUntil.Before(until) {
+		if gap {
+			return errorf(codeVerification, "the oplog archives have a gap after %s; choose an earlier --until", coveredUntil.Format("2006-01-02 15:04:05"))
+		}
+		return fmt.Errorf("the backups in '%s' only cover the oplog up to %s; run 'backup mongo --incremental' or choose an earlier --until", backupDir, coveredUntil.Format("2006-01-02 15:04:05"))
+	}
+
+	fmt.Fprintf(humanOutput, "Snapshot: %s (finished %s)\n", snapshot.Name, snapshot.manifest.FinishedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(humanOutput, "Oplog archives to replay: %d\n", len(replay))
+	fmt.Fprintf(humanOutput, "Target time: %s\n", until.Format("2006-01-02 15:04:05"))
+
+	// 3. بازیابی تا یک لحظه مشخص، داده‌های فعلی را کاملاً جایگزین می‌کند
+	drop, err := dropExisting(cmd)
+	if err != nil {
+		return err
+	}
+	if !drop {
+		return errorf(codeUsage, "--until restores the state at a point in time and needs --drop to replace the existing data")
+	}
+	verified := true
+	for _, a := range replay {
+		ok, err := verifyBackupFile(cmd, a.Path)
+		if err != nil {
+			return err
+		}
+		verified = verified && ok
+	}
+
+	// 4. بازیابی snapshot همراه با oplog خودش
+	eng := &mongodb.Engine{ToolsPath: toolsPath, Oplog: true}
+	result, err := restoreArchive(ctx, cmd, restoreRequest{
+		Engine:      eng,
+		URI:         uri,
+		Archive:     snapshot.Path,
+		Namespaces:  describeRestore(eng, snapshot.Path),
+		Drop:        true,
+		SnapshotDir: snapshotDirIfRequested(cmd, backupDir),
+	})
+	if err != nil {
+		return err
+	}
+
+	// 5. بازپخش ورودی‌های oplog بعد از snapshot و پیش از زمان هدف
+	startedAt := time.Now()
+	limit := mongodb.TimestampAt(until)
+	entries := 0
+	if len(replay) > 0 {
+		dir, err := os.MkdirTemp("", "dataweaver-oplog-")
+		if err != nil {
+			return fmt.Errorf("failed to create a temporary directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		entries, err = writeOplogFile(filepath.Join(dir, mongodb.OplogFile), replay, snapshot.end, limit)
+		if err != nil {
+			return errorf(codeVerification, "failed to read the oplog archives: %w%s", err, undoHint(result.Snapshot))
+		}
+		if entries > 0 {
+			fmt.Fprintf(humanOutput, "Replaying %d oplog entries up to %s...\n", entries, limit)
+			err = eng.ReplayOplog(ctx, uri, dir, limit, humanOutput)
+			if ctxErr := interrupted(ctx, "oplog replay"); ctxErr != nil {
+				return fmt.Errorf("%w; the target database may be partially restored%s", ctxErr, undoHint(result.Snapshot))
+			}
+			if err != nil {
+				return errorf(codeToolFailed, "oplog replay failed: %w%s", err, undoHint(result.Snapshot))
+			}
+		}
+	}
+	if entries == 0 {
+		fmt.Fprintln(humanOutput, "No oplog entries to replay after the snapshot.")
+	}
+
+	fmt.Fprintln(humanOutput, "------------------------")
+	fmt.Fprintln(humanOutput, "Point-in-time restore completed successfully!")
+	result.Verified = result.Verified && verified
+	result.Until = until.Format(time.RFC3339)
+	result.OplogEntries = entries
+	for _, s := range replay {
+		result.OplogArchives = append(result.OplogArchives, s.Name)
+	}
+	result.DurationSeconds += time.Since(startedAt).Round(time.Millisecond).Seconds()
+	setResult(result)
+	return nil
+}
+
+// writeOplogFile writes the oplog entries of archives that come after the position
+// after and before limit to path, skipping entries already written, and returns their
+// number.
+func writeOplogFile(path string, archives []oplogArchive, after, limit mongodb.Timestamp) (int, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+
+	n := 0
+	for _, a := range archives {
+		err := func() error {
+			archive, err := openArchive(a.Path)
+			if err != nil {
+				return err
+			}
+			defer archive.Close()
+			r, err := mongodb.NewArchiveReader(archive)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			return mongodb.ReadOplog(r, func(ts mongodb.Timestamp, entry []byte) error {
+				if !after.Before(ts) || !ts.Before(limit) {
+					return nil
+				}
+				after = ts
+				n++
+				_, err := w.Write(entry)
+				return err
+			})
+		}()
+		if err != nil {
+			return n, fmt.Errorf("'%s': %w", a.Name, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return n, err
+	}
+	return n, f.Close()
+}
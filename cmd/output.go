@@ -0,0 +1,222 @@
+// فایل: cmd/output.go
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+	"github.com/mshamsi502/dataweaver-cli/internal/redact"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+// Error codes reported in the "error.code" field of JSON output. Each code has its own
+// exit status (see exitCodes).
+const (
+	codeUsage        = "usage_error"         // invalid flags or arguments
+	codeConfig       = "config_error"        // missing or invalid configuration
+	codeToolMissing  = "tool_missing"        // external tools not found
+	codeConnection   = "connection_failed"   // the database server could not be reached
+	codeToolFailed   = "tool_failed"         // mongodump, pg_restore, ... failed
+	codeVerification = "verification_failed" // checksum or decryption check failed
+	codeStorage      = "storage_error"       // remote storage unreachable or failing
+	codeBusy         = "backup_locked"       // another backup is running
+	codeTimeout      = "timeout"             // --timeout expired
+	codeCancelled    = "cancelled"           // cancelled by the user or by a signal
+	codeFailed       = "error"               // anything else
+)
+
+// exitCodes maps the error codes to the exit status of the process.
+var exitCodes = map[string]int{
+	codeFailed:       1,
+	codeUsage:        2,
+	codeConfig:       3,
+	codeToolMissing:  4,
+	codeConnection:   5,
+	codeToolFailed:   6,
+	codeVerification: 7,
+	codeStorage:      8,
+	codeBusy:         9,
+	codeTimeout:      10,
+	codeCancelled:    130, // مانند پردازه‌ای که با Ctrl+C متوقف شده
+}
+
+// codedError attaches an error code to an error.
+type codedError struct {
+	code string
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// withCode returns err with the given error code, or nil if err is nil.
+func withCode(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{code: code, err: err}
+}
+
+// errorf is fmt.Errorf with an error code.
+func errorf(code, format string, args ...any) error {
+	return withCode(code, fmt.Errorf(format, args...))
+}
+
+// errorCode returns the code of the outermost coded error in err's chain, or codeFailed.
+// Failures of the dump and restore tools caused by an unreachable server are reported
+// as codeConnection.
+func errorCode(err error) string {
+	var coded *codedError
+	if !errors.As(err, &coded) {
+		return codeFailed
+	}
+	if coded.code == codeToolFailed && errors.Is(err, engine.ErrConnection) {
+		return codeConnection
+	}
+	return coded.code
+}
+
+// exitCode returns the exit status for err.
+func exitCode(err error) int {
+	if code, ok := exitCodes[errorCode(err)]; ok {
+		return code
+	}
+	return 1
+}
+
+// jsonOutput is set by '--output json'. Human readable output then goes to stderr
+// and stdout only carries the JSON document.
+var jsonOutput bool
+
+// resultOutput is where the JSON document is written.
+var resultOutput io.Writer = os.Stdout
+
+// humanOutput is where commands write their human readable output: stdout, or
+// stderr with '--output json' so that stdout only carries the JSON document.
+var humanOutput io.Writer = os.Stdout
+
+// commandOutput is the JSON document printed for a command.
+type commandOutput struct {
+	Command         string       `json:"command"`
+	Status          string       `json:"status"` // "ok" or "error"
+	Profile         string       `json:"profile,omitempty"`
+	DurationSeconds float64      `json:"duration_seconds"`
+	Result          any          `json:"result,omitempty"`
+	Error           *outputError `json:"error,omitempty"`
+}
+
+type outputError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+var (
+	commandStartedAt time.Time
+	commandName      string
+	commandResult    any
+	// printedLines is set once printJSONLine was used; the final document is then omitted.
+	printedLines bool
+)
+
+// setupOutput applies the --output flag before a command runs.
+func setupOutput(cmd *cobra.Command) error {
+	commandStartedAt = time.Now()
+	commandName = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	format, _ := cmd.Flags().GetString("output")
+	switch format {
+	case "", "text":
+		return nil
+	case "json":
+	default:
+		return withCode(codeUsage, fmt.Errorf("invalid --output %q (expected text or json)", format))
+	}
+	jsonOutput = true
+	// خروجی متنی به stderr می‌رود تا stdout فقط شامل JSON باشد
+	humanOutput = os.Stderr
+	return nil
+}
+
+// askOne is survey.AskOne with the prompt drawn on stderr in JSON mode, so that
+// stdout only carries the JSON document.
+func askOne(p survey.Prompt, response any, opts ...survey.AskOpt) error {
+	if jsonOutput {
+		opts = append([]survey.AskOpt{survey.WithStdio(os.Stdin, os.Stderr, os.Stderr)}, opts...)
+	}
+	return survey.AskOne(p, response, opts...)
+}
+
+// setResult records the structured result of the running command.
+func setResult(result any) {
+	commandResult = result
+}
+
+// printResult writes the JSON document of a successful command.
+func printResult() {
+	if jsonOutput && !printedLines {
+		writeOutput(commandOutput{Status: "ok", Result: commandResult})
+	}
+}
+
+// printJSONLine writes one JSON document per line, for commands that report several
+// results (e.g. the daemon).
+func printJSONLine(v any) {
+	if !jsonOutput {
+		return
+	}
+	printedLines = true
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Warning: cannot encode JSON output: %v", err)
+		return
+	}
+	fmt.Fprintln(resultOutput, redact.String(string(data)))
+}
+
+func writeOutput(out commandOutput) {
+	out.Command = commandName
+	out.Profile = config.ActiveProfile()
+	if !commandStartedAt.IsZero() {
+		out.DurationSeconds = time.Since(commandStartedAt).Round(time.Millisecond).Seconds()
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		log.Printf("Warning: cannot encode JSON output: %v", err)
+		return
+	}
+	fmt.Fprintln(resultOutput, redact.String(string(data)))
+}
+
+// reportError prints err to stderr and, in JSON mode, as the command's JSON document.
+// A result recorded before the error (e.g. a failed verification report) is included.
+func reportError(err error) {
+	log.Print(err)
+	if jsonOutput {
+		writeOutput(commandOutput{Status: "error", Result: commandResult, Error: &outputError{Code: errorCode(err), Message: err.Error()}})
+	}
+}
+
+// jsonOutputRequested reports whether args ask for JSON output. It is used when cobra
+// fails before the flags are parsed.
+func jsonOutputRequested(args []string) bool {
+	for i, arg := range args {
+		switch arg {
+		case "--output=json", "-o=json", "-ojson":
+			return true
+		case "--output", "-o":
+			if i+1 < len(args) && args[i+1] == "json" {
+				return true
+			}
+		}
+	}
+	return false
+}
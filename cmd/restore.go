@@ -11,7 +11,7 @@ var restoreCmd = &cobra.Command{
 	Use:   "restore",
 	Short: "Restore data from a backup",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Please specify a subcommand, e.g., 'mongo'.")
+		fmt.Fprintln(humanOutput, "Please specify a subcommand, e.g., 'mongo'.")
 	},
 }
 
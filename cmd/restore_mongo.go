@@ -3,16 +3,13 @@ package cmd
 
 import (
 	"fmt"
-	"log"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"runtime"
 	"strings"
 
-	"github.com/AlecAivazis/survey/v2"
+	"github.com/mshamsi502/dataweaver-cli/internal/backup"
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/mongodb"
+
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // restoreMongoCmd represents the mongo subcommand of restore
@@ -20,90 +17,163 @@ var restoreMongoCmd = &cobra.Command{
 	Use:   "mongo",
 	Short: "Restore a MongoDB database from a backup file",
 	Long: `Restores a MongoDB database from a previously created archive file.
-You will be prompted to select a backup file from the configured backup directory.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Starting MongoDB restore...")
+You will be prompted to select a backup file from the configured backup directory.
+
+For scripts and scheduled jobs, pick the archive with --file, --latest or
+--before <timestamp> and skip the confirmation with --yes. When stdin is not a
+terminal the command fails instead of prompting.
+
+Existing collections are only dropped with --drop (or 'restore.drop'); otherwise
+the archive is restored on top of them. --snapshot backs up the target first so
+the restore can be rolled back with 'restore undo'.
+
+With --until <timestamp> the newest snapshot taken with 'backup mongo --oplog'
+before that time is restored and the oplog archived by 'backup mongo --incremental'
+is replayed up to it (mongorestore --oplogReplay --oplogLimit). It requires --drop.
+Only archives in the local backup directory are used.
+
+Namespaces can be restored under other names instead of overwriting the originals:
+  --target-db app_prod_copy              # every collection of the archive's database
+  --ns-from 'app.*' --ns-to 'copy.*'     # pairs of patterns with '*' wildcards
+--ns-include and --ns-exclude restore only part of the archive. Without these flags
+the 'mongodb.restore.*' config keys are used (target_db, ns_map, ns_include,
+ns_exclude).
+
+After mongorestore finishes, the restored collections are compared with the archive:
+the number of documents and the indexes mongodump recorded. A PASS/FAIL line is
+printed per collection (and the report is part of '--output json'); a mismatch makes
+the command fail with 'verification_failed'. Restores without --drop accept more
+documents than the archive holds. The check needs mongosh and is skipped with a
+warning when it is not installed, or with --skip-report.
+
+With --staged (or 'mongodb.restore.staged') the archive is restored into staging
+collections inside the target databases ("<db>.__dwstaging.<collection>"). The
+staged collections are compared with the archive (document counts and indexes)
+and only then renamed over the target collections with renameCollection and
+dropTarget. Each rename stays within its database, so it only changes metadata
+and replaces its target in one step; the collections are swapped one after
+another, not all at once. If the restore or the check fails, the targets are
+left untouched. --staged needs mongosh and always replaces the restored
+collections, so --drop does not apply; other collections of the target are kept.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Fprintln(humanOutput, "Starting MongoDB restore...")
+		if profile := config.ActiveProfile(); profile != "" {
+			fmt.Fprintf(humanOutput, "Using profile: %s\n", profile)
+		}
 
 		// 1. خواندن تنظیمات
-		localURI := viper.GetString("mongodb.local_uri")
-		toolsPath := viper.GetString("paths.mongo_tools")
-		backupDir := viper.GetString("paths.backup")
+		localURI, err := uriFromConfig("mongodb.local_uri")
+		if err != nil {
+			return err
+		}
+		backupDir := config.GetString("paths.backup")
 
 		// 2. بررسی تنظیمات ضروری
-		if localURI == "" || toolsPath == "" || backupDir == "" {
-			log.Fatal("Configuration error: 'mongodb.local_uri', 'paths.mongo_tools', and 'paths.backup' must be set.")
+		if localURI == "" || backupDir == "" {
+			return errorf(codeConfig, "configuration error: 'mongodb.local_uri' and 'paths.backup' must be set")
 		}
 
-		// 3. پیدا کردن فایل‌های بکاپ موجود
-		backupFiles, err := findBackupFiles(backupDir)
+		// 3. پیدا کردن mongorestore (مسیر تنظیم‌شده، PATH یا مسیرهای شناخته‌شده)
+		toolsPath, err := findMongoTools()
 		if err != nil {
-			log.Fatalf("Error reading backup directory '%s': %v", backupDir, err)
-		}
-		if len(backupFiles) == 0 {
-			log.Fatalf("No backup files found in '%s'.", backupDir)
+			return err
 		}
 
-		// 4. گرفتن ورودی از کاربر برای انتخاب فایل بکاپ
-		var selectedFile string
-		prompt := &survey.Select{
-			Message: "Choose a backup to restore:",
-			Options: backupFiles,
+		// 4. تغییر نام namespaceها (فلگ‌ها یا قوانین کانفیگ)
+		remap, err := mongoRemapFromFlags(cmd)
+		if err != nil {
+			return withCode(codeConfig, err)
 		}
-		survey.AskOne(prompt, &selectedFile, survey.WithValidator(survey.Required))
-
-		backupFilePath := filepath.Join(backupDir, selectedFile)
-		fmt.Printf("Selected backup file: %s\n", backupFilePath)
-
-		// 5. ساختن و اجرای دستور mongorestore
-		mongoRestoreExecutable := "mongorestore"
-		if runtime.GOOS == "windows" {
-			mongoRestoreExecutable += ".exe"
+		if err := remap.Validate(); err != nil {
+			return errorf(codeUsage, "invalid namespace remapping: %w", err)
 		}
-		mongoRestorePath := filepath.Join(toolsPath, mongoRestoreExecutable)
 
-		if _, err := os.Stat(mongoRestorePath); os.IsNotExist(err) {
-			log.Fatalf("mongorestore not found at the specified path: %s. Please verify your 'paths.mongo_tools' configuration.", mongoRestorePath)
+		// 5. بازیابی تا یک لحظه مشخص با بازپخش oplog
+		if until, _ := cmd.Flags().GetString("until"); until != "" {
+			if !remap.IsEmpty() {
+				return errorf(codeUsage, "--until cannot be combined with namespace remapping (--ns-from, --target-db, --ns-include, --ns-exclude or 'mongodb.restore.*')")
+			}
+			t, err := backup.ParseTime(until)
+			if err != nil {
+				return withCode(codeUsage, err)
+			}
+			return restoreMongoUntil(cmd, toolsPath, localURI, backupDir, t)
 		}
 
-		// The --drop flag will drop collections from the target database before restoring.
-		restoreCmd := exec.Command(mongoRestorePath,
-			fmt.Sprintf("--uri=%s", localURI),
-			fmt.Sprintf("--archive=%s", backupFilePath),
-			"--gzip",
-			"--drop",
-		)
-
-		fmt.Println("Executing mongorestore command. This might take a while...")
-
-		// اجرای دستور و نمایش خروجی به صورت زنده
-		restoreCmd.Stdout = os.Stdout
-		restoreCmd.Stderr = os.Stderr
-
-		if err := restoreCmd.Run(); err != nil {
-			log.Fatalf("mongorestore command failed: %v", err)
+		eng := &mongodb.Engine{ToolsPath: toolsPath, Remap: remap}
+		if boolFlagOrConfig(cmd, "staged", "mongodb.restore.staged") {
+			// 6. بازیابی در پایگاه داده موقت و جابه‌جایی پس از بررسی
+			return restoreMongoStaged(cmd, eng, localURI, backupDir)
 		}
-
-		fmt.Println("------------------------")
-		fmt.Println("MongoDB restore completed successfully!")
+		return runRestore(cmd, eng, localURI, backupDir)
 	},
 }
 
-// findBackupFiles finds all .gz backup files in the specified directory
-func findBackupFiles(backupDir string) ([]string, error) {
-	var files []string
-	entries, err := os.ReadDir(backupDir)
-	if err != nil {
-		return nil, err
+// mongoRemapFromFlags builds the namespace remapping of a restore from the flags,
+// falling back to the 'mongodb.restore.*' config keys. Renaming rules given as flags
+// replace the configured ones.
+func mongoRemapFromFlags(cmd *cobra.Command) (mongodb.Remap, error) {
+	remap := mongodb.Remap{
+		Include: stringSliceFlagOrConfig(cmd, "ns-include", "mongodb.restore.ns_include"),
+		Exclude: stringSliceFlagOrConfig(cmd, "ns-exclude", "mongodb.restore.ns_exclude"),
+	}
+	changed := false
+	for _, name := range []string{"ns-from", "ns-to", "from-ns", "to-ns", "target-db"} {
+		changed = changed || cmd.Flags().Changed(name)
+	}
+	if changed {
+		// --from-ns/--to-ns نام‌های قدیمی همین فلگ‌ها هستند
+		from, _ := cmd.Flags().GetStringArray("ns-from")
+		oldFrom, _ := cmd.Flags().GetStringArray("from-ns")
+		to, _ := cmd.Flags().GetStringArray("ns-to")
+		oldTo, _ := cmd.Flags().GetStringArray("to-ns")
+		remap.From, remap.To = append(from, oldFrom...), append(to, oldTo...)
+		remap.TargetDB, _ = cmd.Flags().GetString("target-db")
+		return remap, nil
 	}
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".gz") {
-			files = append(files, entry.Name())
+
+	remap.TargetDB = config.GetString("mongodb.restore.target_db")
+	var err error
+	remap.From, remap.To, err = parseNamespaceMap(config.GetStringSlice("mongodb.restore.ns_map"))
+	return remap, err
+}
+
+// parseNamespaceMap parses the 'mongodb.restore.ns_map' rules, written as
+// "app.* -> app_copy.*", into pairs of --ns-from/--ns-to patterns.
+func parseNamespaceMap(rules []string) (from, to []string, err error) {
+	for _, rule := range rules {
+		f, t, ok := strings.Cut(rule, "->")
+		f, t = strings.TrimSpace(f), strings.TrimSpace(t)
+		if !ok || f == "" || t == "" || strings.Contains(t, "->") {
+			return nil, nil, fmt.Errorf("configuration error: mongodb.restore.ns_map: invalid rule %q (expected \"<from> -> <to>\")", rule)
 		}
+		from, to = append(from, f), append(to, t)
 	}
-	return files, nil
+	return from, to, nil
 }
 
 func init() {
 	// اضافه کردن این زیردستور به دستور والد 'restore'
 	restoreCmd.AddCommand(restoreMongoCmd)
+
+	addRestoreSelectionFlags(restoreMongoCmd)
+	restoreMongoCmd.Flags().String("until", "", "Restore the state at this time from a snapshot with oplog plus the archived oplog (e.g. 2025-06-08_14-30-00)")
+	restoreMongoCmd.MarkFlagsMutuallyExclusive("until", "file", "latest", "before")
+	restoreMongoCmd.Flags().Bool("skip-report", false, "Do not compare the restored collections with the archive after restoring")
+	restoreMongoCmd.Flags().Bool("staged", false, "Restore into staging collections, verify them and then swap them into place")
+	restoreMongoCmd.MarkFlagsMutuallyExclusive("staged", "until")
+	restoreMongoCmd.MarkFlagsMutuallyExclusive("staged", "drop")
+	restoreMongoCmd.Flags().StringArray("ns-from", nil, "Namespace pattern to restore under another name (repeatable, paired with --ns-to)")
+	restoreMongoCmd.Flags().StringArray("ns-to", nil, "Target namespace pattern (repeatable, paired with --ns-from)")
+	// --from-ns/--to-ns were the first spelling of --ns-from/--ns-to, which 'clone mongo' uses
+	restoreMongoCmd.Flags().StringArray("from-ns", nil, "Deprecated alias of --ns-from")
+	restoreMongoCmd.Flags().StringArray("to-ns", nil, "Deprecated alias of --ns-to")
+	restoreMongoCmd.Flags().MarkDeprecated("from-ns", "use --ns-from instead")
+	restoreMongoCmd.Flags().MarkDeprecated("to-ns", "use --ns-to instead")
+	restoreMongoCmd.Flags().String("target-db", "", "Restore the collections of the archive's database into this database")
+	restoreMongoCmd.Flags().StringSlice("ns-include", nil, "Only restore namespaces matching this pattern (repeatable, e.g. 'app.*')")
+	restoreMongoCmd.Flags().StringSlice("ns-exclude", nil, "Do not restore namespaces matching this pattern (repeatable)")
+	restoreMongoCmd.MarkFlagsMutuallyExclusive("target-db", "ns-from")
+	restoreMongoCmd.MarkFlagsMutuallyExclusive("target-db", "from-ns")
+	addTimeoutFlag(restoreMongoCmd)
 }
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNamespaceMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []string
+		wantFrom []string
+		wantTo   []string
+		wantErr  bool
+	}{
+		{"none", nil, nil, nil, false},
+		{"one rule", []string{"app.* -> app_copy.*"}, []string{"app.*"}, []string{"app_copy.*"}, false},
+		{"without spaces", []string{"app.users->app.people"}, []string{"app.users"}, []string{"app.people"}, false},
+		{"several rules", []string{"app.* -> a.*", " shop.* ->  s.* "}, []string{"app.*", "shop.*"}, []string{"a.*", "s.*"}, false},
+		{"missing arrow", []string{"app.* app_copy.*"}, nil, nil, true},
+		{"wrong arrow", []string{"app.* => app_copy.*"}, nil, nil, true},
+		{"missing source", []string{" -> app_copy.*"}, nil, nil, true},
+		{"missing target", []string{"app.* -> "}, nil, nil, true},
+		{"two arrows", []string{"app.* -> a.* -> b.*"}, nil, nil, true},
+		{"one bad rule among good ones", []string{"app.* -> a.*", "shop.*"}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := parseNamespaceMap(tt.rules)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseNamespaceMap(%q) = %v, %v; want an error", tt.rules, from, to)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseNamespaceMap(%q): %v", tt.rules, err)
+			}
+			if !reflect.DeepEqual(from, tt.wantFrom) || !reflect.DeepEqual(to, tt.wantTo) {
+				t.Fatalf("parseNamespaceMap(%q) = %v, %v; want %v, %v", tt.rules, from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
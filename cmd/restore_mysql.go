@@ -0,0 +1,48 @@
+// فایل: cmd/restore_mysql.go
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/mysql"
+
+	"github.com/spf13/cobra"
+)
+
+// restoreMySQLCmd represents the mysql subcommand of restore
+var restoreMySQLCmd = &cobra.Command{
+	Use:     "mysql",
+	Aliases: []string{"mariadb"},
+	Short:   "Restore a MySQL or MariaDB database from a backup file",
+	Long: `Restores a SQL dump created by 'backup mysql' by streaming it into the mysql
+(or mariadb) client connected to 'mysql.local_uri'. The dump recreates the backed up
+databases; existing tables with the same names are only dropped with --drop.
+
+The archive is selected exactly like for 'restore mongo'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Fprintln(humanOutput, "Starting MySQL restore...")
+		if profile := config.ActiveProfile(); profile != "" {
+			fmt.Fprintf(humanOutput, "Using profile: %s\n", profile)
+		}
+
+		localURI, err := uriFromConfig("mysql.local_uri")
+		if err != nil {
+			return err
+		}
+		toolsPath := config.GetString("paths.mysql_tools")
+		backupDir := config.GetString("paths.backup")
+
+		if localURI == "" || backupDir == "" {
+			return errorf(codeConfig, "configuration error: 'mysql.local_uri' and 'paths.backup' must be set")
+		}
+
+		return runRestore(cmd, &mysql.Engine{ToolsPath: toolsPath}, localURI, backupDir)
+	},
+}
+
+func init() {
+	restoreCmd.AddCommand(restoreMySQLCmd)
+	addRestoreSelectionFlags(restoreMySQLCmd)
+	addTimeoutFlag(restoreMySQLCmd)
+}
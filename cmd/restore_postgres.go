@@ -0,0 +1,48 @@
+// فایل: cmd/restore_postgres.go
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/postgres"
+
+	"github.com/spf13/cobra"
+)
+
+// restorePostgresCmd represents the postgres subcommand of restore
+var restorePostgresCmd = &cobra.Command{
+	Use:   "postgres",
+	Short: "Restore a PostgreSQL database from a backup file",
+	Long: `Restores a PostgreSQL database from a custom-format archive created by
+'backup postgres', using pg_restore. Ownership is not restored (--no-owner).
+With --drop the existing objects are dropped first (--clean --if-exists).
+
+The target is 'postgres.local_uri'. The archive is selected exactly like for
+'restore mongo'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Fprintln(humanOutput, "Starting PostgreSQL restore...")
+		if profile := config.ActiveProfile(); profile != "" {
+			fmt.Fprintf(humanOutput, "Using profile: %s\n", profile)
+		}
+
+		localURI, err := uriFromConfig("postgres.local_uri")
+		if err != nil {
+			return err
+		}
+		toolsPath := config.GetString("paths.postgres_tools")
+		backupDir := config.GetString("paths.backup")
+
+		if localURI == "" || backupDir == "" {
+			return errorf(codeConfig, "configuration error: 'postgres.local_uri' and 'paths.backup' must be set")
+		}
+
+		return runRestore(cmd, &postgres.Engine{ToolsPath: toolsPath}, localURI, backupDir)
+	},
+}
+
+func init() {
+	restoreCmd.AddCommand(restorePostgresCmd)
+	addRestoreSelectionFlags(restorePostgresCmd)
+	addTimeoutFlag(restorePostgresCmd)
+}
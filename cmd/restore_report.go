@@ -0,0 +1,171 @@
+// فایل: cmd/restore_report.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+	"github.com/mshamsi502/dataweaver-cli/internal/mongodb"
+
+	"github.com/spf13/cobra"
+)
+
+// restoreReport compares the restored collections with the archive, reported by
+// 'restore mongo' and '--output json'.
+type restoreReport struct {
+	Passed      bool               `json:"passed"`
+	Collections []collectionReport `json:"collections"`
+}
+
+// collectionReport is the verification of one restored collection.
+type collectionReport struct {
+	Namespace string `json:"namespace"`
+	// Source is the name of the collection in the archive, if it was renamed.
+	Source            string          `json:"source,omitempty"`
+	ExpectedDocuments int64           `json:"expected_documents"`
+	Documents         int64           `json:"documents"`
+	ExpectedIndexes   []mongodb.Index `json:"expected_indexes"`
+	Indexes           []mongodb.Index `json:"indexes"`
+	Problems          []string        `json:"problems,omitempty"`
+	Passed            bool            `json:"passed"`
+}
+
+// newRestoreReport compares the statistics of the restored collections (actual, in
+// the order of targets) with those of the archive namespaces sources. If merged is
+// set, the archive was restored on top of existing data (see CollectionStats.Compare).
+func newRestoreReport(sources, targets []string, expected map[string]mongodb.CollectionStats, actual []mongodb.CollectionStats, merged bool) *restoreReport {
+	report := &restoreReport{Passed: true}
+	for i, target := range targets {
+		want := expected[sources[i]]
+		c := collectionReport{
+			Namespace:         target,
+			ExpectedDocuments: want.Documents,
+			Documents:         actual[i].Documents,
+			ExpectedIndexes:   want.Indexes,
+			Indexes:           actual[i].Indexes,
+			Problems:          want.Compare(actual[i], merged),
+		}
+		if sources[i] != target {
+			c.Source = sources[i]
+		}
+		c.Passed = len(c.Problems) == 0
+		report.Passed = report.Passed && c.Passed
+		report.Collections = append(report.Collections, c)
+	}
+	return report
+}
+
+// failed returns the number of collections that did not pass.
+func (r *restoreReport) failed() int {
+	n := 0
+	for _, c := range r.Collections {
+		if !c.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// print writes the report as a table, one line per collection.
+func (r *restoreReport) print() {
+	width := 0
+	for _, c := range r.Collections {
+		width = max(width, len(c.Namespace))
+	}
+	fmt.Fprintln(humanOutput, "Verification report:")
+	for _, c := range r.Collections {
+		status := "PASS"
+		if !c.Passed {
+			status = "FAIL"
+		}
+		present := 0
+		for _, want := range c.ExpectedIndexes {
+			for _, idx := range c.Indexes {
+				if idx == want {
+					present++
+					break
+				}
+			}
+		}
+		line := fmt.Sprintf("  %s  %-*s  %d/%d documents, %d/%d indexes", status, width, c.Namespace, c.Documents, c.ExpectedDocuments, present, len(c.ExpectedIndexes))
+		if len(c.Problems) > 0 {
+			line += ": " + strings.Join(c.Problems, "; ")
+		}
+		fmt.Fprintln(humanOutput, line)
+	}
+	if r.Passed {
+		fmt.Fprintf(humanOutput, "All %d collections match the archive.\n", len(r.Collections))
+	} else {
+		fmt.Fprintf(humanOutput, "%d of %d collections do not match the archive.\n", r.failed(), len(r.Collections))
+	}
+}
+
+// restoredPairs returns the archive namespaces that eng restores and the names they
+// are restored as, in the same order.
+func restoredPairs(eng *mongodb.Engine, contents []string) (sources, targets []string) {
+	for _, ns := range contents {
+		if target, ok := eng.RestoredName(ns); ok {
+			sources = append(sources, ns)
+			targets = append(targets, target)
+		}
+	}
+	return sources, targets
+}
+
+// reportRequested reports whether the verification report runs after restoring with
+// eng: only for MongoDB, not with --skip-report and only if mongosh is installed.
+func reportRequested(cmd *cobra.Command, eng engine.Engine) bool {
+	e, ok := eng.(*mongodb.Engine)
+	if !ok {
+		return false
+	}
+	if skip, _ := cmd.Flags().GetBool("skip-report"); skip {
+		fmt.Fprintln(humanOutput, "Skipping the verification report (--skip-report).")
+		return false
+	}
+	if _, err := e.ShellPath(); err != nil {
+		log.Printf("Warning: the restore cannot be verified: %v", err)
+		return false
+	}
+	return true
+}
+
+// verifyRestore compares the collections restored into the deployment behind uri
+// with the statistics of the archive collected while it was restored (see
+// restoreRequest.Count): document counts and index definitions.
+func verifyRestore(ctx context.Context, e *mongodb.Engine, uri string, archive []mongodb.CollectionStats, merged bool) (*restoreReport, error) {
+	// 1. کالکشن‌های آرشیو و نام‌هایی که با آن‌ها بازیابی شده‌اند
+	fmt.Fprintln(humanOutput, "Verifying the restored collections against the archive...")
+	contents := make([]string, 0, len(archive))
+	for _, c := range archive {
+		contents = append(contents, c.Namespace)
+	}
+	sources, targets := restoredPairs(e, contents)
+	if len(targets) == 0 {
+		return &restoreReport{Passed: true}, nil
+	}
+
+	// 2. آمار همان کالکشن‌ها در مقصد
+	actual, err := e.CollectionStats(ctx, uri, targets)
+	if ctxErr := interrupted(ctx, "verification"); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, errorf(codeToolFailed, "the restore finished but the restored collections could not be inspected: %w", err)
+	}
+	report := newRestoreReport(sources, targets, statsByNamespace(archive), actual, merged)
+	report.print()
+	return report, nil
+}
+
+// statsByNamespace indexes collection statistics by namespace.
+func statsByNamespace(stats []mongodb.CollectionStats) map[string]mongodb.CollectionStats {
+	byNamespace := make(map[string]mongodb.CollectionStats, len(stats))
+	for _, s := range stats {
+		byNamespace[s.Namespace] = s
+	}
+	return byNamespace
+}
@@ -0,0 +1,536 @@
+// فایل: cmd/restore_run.go
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/backup"
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+	"github.com/mshamsi502/dataweaver-cli/internal/mongodb"
+	"github.com/mshamsi502/dataweaver-cli/internal/storage"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// restoreResult is the outcome of a restore, reported by '--output json'.
+type restoreResult struct {
+	Engine        string   `json:"engine"`
+	Archive       string   `json:"archive"`
+	Target        string   `json:"target"`
+	Namespaces    []string `json:"namespaces,omitempty"`
+	Dropped       bool     `json:"dropped"`
+	Staged        bool     `json:"staged,omitempty"`
+	Verified      bool     `json:"verified"`
+	Snapshot      string   `json:"snapshot,omitempty"`
+	Until         string   `json:"until,omitempty"`
+	OplogArchives []string `json:"oplog_archives,omitempty"`
+	OplogEntries  int      `json:"oplog_entries,omitempty"`
+	// Report is the comparison of the restored collections with the archive.
+	Report          *restoreReport `json:"report,omitempty"`
+	DurationSeconds float64        `json:"duration_seconds"`
+
+	// archiveStats are the statistics of the archive collected while it was
+	// restored (see restoreRequest.Count).
+	archiveStats []mongodb.CollectionStats
+}
+
+// safetySnapshotDir is the subdirectory of the backup directory that holds the
+// snapshots taken before a restore (see --snapshot and 'restore undo').
+const safetySnapshotDir = "pre-restore"
+
+// restoreRequest describes the restore of one archive (see restoreArchive).
+type restoreRequest struct {
+	Engine  engine.Engine
+	URI     string
+	Archive string
+	// Namespaces are the names the archive is restored as (see describeRestore).
+	Namespaces []string
+	// Drop replaces the existing data of the restored namespaces.
+	Drop bool
+	// SnapshotDir, if set, receives a safety snapshot of the target before restoring.
+	SnapshotDir string
+	// Count collects the statistics of a MongoDB archive while it is restored, for the
+	// verification report (see verifyRestore).
+	Count bool
+}
+
+// runRestore selects an archive of eng from backupDir (see selectBackupFile) and
+// restores it into the database behind uri (see restoreArchive).
+// Ctrl+C, SIGTERM and --timeout stop the restore tool (see operationContext).
+func runRestore(cmd *cobra.Command, eng engine.Engine, uri, backupDir string) error {
+	ctx, cancel := operationContext(cmd)
+	defer cancel()
+
+	// 1. انتخاب فایل بکاپ (از طریق فلگ‌ها یا به صورت تعاملی)
+	backupFilePath, err := selectBackupFile(ctx, cmd, backupDir, eng.Extension())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(humanOutput, "Selected backup file: %s\n", backupFilePath)
+	namespaces := describeRestore(eng, backupFilePath)
+	if e, ok := eng.(*mongodb.Engine); ok {
+		// --target-db پیش از تأیید و safety snapshot با محتوای آرشیو سنجیده می‌شود
+		if contents := archiveContents(eng, backupFilePath); len(contents) > 0 {
+			if _, err := e.Remap.Resolve(contents); err != nil {
+				return withCode(codeUsage, err)
+			}
+		}
+	}
+
+	// 2. حذف داده‌های فعلی فقط با درخواست صریح انجام می‌شود
+	drop, err := dropExisting(cmd)
+	if err != nil {
+		return err
+	}
+	report := reportRequested(cmd, eng)
+	result, err := restoreArchive(ctx, cmd, restoreRequest{
+		Engine:      eng,
+		URI:         uri,
+		Archive:     backupFilePath,
+		Namespaces:  namespaces,
+		Drop:        drop,
+		SnapshotDir: snapshotDirIfRequested(cmd, backupDir),
+		Count:       report,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 3. مقایسه مقصد با آرشیو؛ گزارش در خروجی JSON خطا هم آورده می‌شود
+	if report {
+		if result.Report, err = verifyRestore(ctx, eng.(*mongodb.Engine), uri, result.archiveStats, !drop); err != nil {
+			return err
+		}
+	}
+	setResult(result)
+	if result.Report != nil && !result.Report.Passed {
+		return errorf(codeVerification, "the restore finished but %d of %d collections do not match the archive", result.Report.failed(), len(result.Report.Collections))
+	}
+	fmt.Fprintln(humanOutput, "------------------------")
+	fmt.Fprintln(humanOutput, "Restore completed successfully!")
+	return nil
+}
+
+// restoreArchive lists what is about to be overwritten, asks for confirmation,
+// verifies the checksum, takes the safety snapshot if requested and restores the
+// archive of req.
+func restoreArchive(ctx context.Context, cmd *cobra.Command, req restoreRequest) (*restoreResult, error) {
+	eng, host := req.Engine, engine.SourceHost(req.URI)
+
+	// 1. فهرست namespaceهایی که بازنویسی می‌شوند و تأیید نهایی
+	if req.Drop {
+		fmt.Fprintf(humanOutput, "These will be dropped in %s and replaced from the archive:\n", host)
+	} else {
+		fmt.Fprintf(humanOutput, "These will be restored into %s on top of the existing data (use --drop to replace it):\n", host)
+	}
+	if len(req.Namespaces) == 0 {
+		fmt.Fprintln(humanOutput, "  (the contents of the archive could not be listed)")
+	}
+	for _, ns := range req.Namespaces {
+		fmt.Fprintf(humanOutput, "  %s\n", ns)
+	}
+	message := fmt.Sprintf("Restore '%s' into %s?", filepath.Base(req.Archive), host)
+	if req.Drop {
+		message += " Existing data will be dropped."
+	}
+	if err := confirmRestore(cmd, message); err != nil {
+		return nil, err
+	}
+
+	// 2. بررسی سلامت فایل بکاپ با checksum ثبت‌شده در manifest
+	verified, err := verifyBackupFile(cmd, req.Archive)
+	if err != nil {
+		return nil, err
+	}
+	if err := eng.CheckTools(); err != nil {
+		return nil, errorf(codeToolMissing, "%w. Please verify your tools path configuration", err)
+	}
+
+	// 3. در صورت درخواست، ابتدا از وضعیت فعلی مقصد بکاپ گرفته می‌شود
+	var snapshot string
+	if req.SnapshotDir != "" {
+		if snapshot, err = takeSafetySnapshot(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
+	// 4. اجرای ابزار بازیابی
+	archive, err := openArchive(req.Archive)
+	if err != nil {
+		return nil, errorf(codeVerification, "failed to open backup file: %w", err)
+	}
+	defer archive.Close()
+	var counter *mongodb.ArchiveCounter
+	var source io.Reader = archive
+	if req.Count {
+		// سندهای آرشیو همزمان با بازیابی شمرده می‌شوند تا آرشیو دوباره خوانده نشود
+		counter = mongodb.CountArchive(archive)
+		defer counter.Close()
+		source = counter
+	}
+	fmt.Fprintf(humanOutput, "Executing %s restore. This might take a while...\n", eng.Name())
+	startedAt := time.Now()
+	err = eng.Restore(ctx, engine.RestoreOptions{
+		URI:     req.URI,
+		Archive: source,
+		Drop:    req.Drop,
+		Output:  humanOutput,
+	})
+	if ctxErr := interrupted(ctx, "restore"); ctxErr != nil {
+		// بازیابی نیمه‌کاره فقط با safety snapshot قابل برگشت است
+		return nil, fmt.Errorf("%w; the target database may be partially restored%s", ctxErr, undoHint(snapshot))
+	}
+	if err != nil {
+		return nil, errorf(codeToolFailed, "restore failed: %w%s", err, undoHint(snapshot))
+	}
+	if snapshot != "" {
+		fmt.Fprintf(humanOutput, "The previous state is kept in '%s'; undo this restore with 'dataweaver-cli restore undo'.\n", snapshot)
+	}
+	var stats []mongodb.CollectionStats
+	if counter != nil {
+		if stats, err = counter.Stats(); err != nil {
+			return nil, errorf(codeVerification, "the restore finished but the archive could not be read for verification: %w", err)
+		}
+	}
+
+	return &restoreResult{
+		Engine:          eng.Name(),
+		Archive:         req.Archive,
+		Target:          host,
+		Namespaces:      req.Namespaces,
+		Dropped:         req.Drop,
+		Verified:        verified,
+		Snapshot:        snapshot,
+		DurationSeconds: time.Since(startedAt).Round(time.Millisecond).Seconds(),
+		archiveStats:    stats,
+	}, nil
+}
+
+// undoHint returns the sentence appended to restore errors when a safety snapshot
+// was taken, and "" otherwise.
+func undoHint(snapshot string) string {
+	if snapshot == "" {
+		return ""
+	}
+	return ". Run 'dataweaver-cli restore undo' to return to the safety snapshot"
+}
+
+// describeRestore prints the contents of an archive and the names they are restored
+// as, and returns the latter.
+func describeRestore(eng engine.Engine, archivePath string) []string {
+	contents := archiveContents(eng, archivePath)
+	if len(contents) > 0 {
+		fmt.Fprintf(humanOutput, "Archive contains: %s\n", strings.Join(contents, ", "))
+	}
+	restored := restoredNamespaces(eng, contents)
+	if strings.Join(restored, ",") != strings.Join(contents, ",") {
+		fmt.Fprintf(humanOutput, "Restoring as: %s\n", strings.Join(restored, ", "))
+	}
+	return restored
+}
+
+// dropExisting decides whether a restore replaces the existing data: --drop, then
+// 'restore.drop', and otherwise the user is asked if the restore is interactive.
+func dropExisting(cmd *cobra.Command) (bool, error) {
+	if cmd.Flags().Changed("drop") || config.IsSet("restore.drop") {
+		return boolFlagOrConfig(cmd, "drop", "restore.drop"), nil
+	}
+	if yes, _ := cmd.Flags().GetBool("yes"); yes || !isInteractive() {
+		return false, nil
+	}
+	drop := false
+	prompt := &survey.Confirm{Message: "Drop the existing data before restoring? Otherwise the archive is restored on top of it."}
+	if err := askOne(prompt, &drop); err != nil {
+		return false, withCode(codeCancelled, fmt.Errorf("restore cancelled: %w", err))
+	}
+	return drop, nil
+}
+
+// snapshotDirIfRequested returns the directory of the safety snapshot if --snapshot
+// (or 'restore.snapshot') is set, and "" otherwise.
+func snapshotDirIfRequested(cmd *cobra.Command, backupDir string) string {
+	if !boolFlagOrConfig(cmd, "snapshot", "restore.snapshot") {
+		return ""
+	}
+	return filepath.Join(backupDir, safetySnapshotDir)
+}
+
+// takeSafetySnapshot backs up the target of req into req.SnapshotDir and returns the
+// path of the archive. Its manifest names the archive about to be restored.
+func takeSafetySnapshot(ctx context.Context, req restoreRequest) (string, error) {
+	fmt.Fprintf(humanOutput, "Taking a safety snapshot of %s before restoring...\n", engine.SourceHost(req.URI))
+	result, err := runBackup(ctx, snapshotEngine(req.Engine, req.Namespaces), req.URI, req.SnapshotDir)
+	if err != nil {
+		return "", fmt.Errorf("safety snapshot failed, nothing was restored: %w", err)
+	}
+	m, err := backup.ReadManifest(result.Archive)
+	if err == nil {
+		m.SafetySnapshotFor = filepath.Base(req.Archive)
+		err = backup.WriteManifest(result.Archive, m)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to update the manifest of the safety snapshot: %w", err)
+	}
+	return result.Archive, nil
+}
+
+// snapshotEngine returns the engine that backs up the target before a restore. For
+// MongoDB the snapshot is limited to the restored database if there is a single one.
+func snapshotEngine(eng engine.Engine, namespaces []string) engine.Engine {
+	e, ok := eng.(*mongodb.Engine)
+	if !ok {
+		return eng
+	}
+	snapshot := &mongodb.Engine{ToolsPath: e.ToolsPath}
+	databases := map[string]bool{}
+	for _, ns := range namespaces {
+		if db, _, ok := strings.Cut(ns, "."); ok {
+			databases[db] = true
+		}
+	}
+	if len(databases) == 1 {
+		for db := range databases {
+			snapshot.Selection.Database = db
+		}
+	}
+	return snapshot
+}
+
+// restoredNamespaces returns the names the contents of an archive are restored as,
+// for engines that can rename or skip them (see engine.Renamer).
+func restoredNamespaces(eng engine.Engine, contents []string) []string {
+	r, ok := eng.(engine.Renamer)
+	if !ok {
+		return contents
+	}
+	var restored []string
+	for _, name := range contents {
+		if target, ok := r.RestoredName(name); ok {
+			restored = append(restored, target)
+		}
+	}
+	return restored
+}
+
+// selectBackupFile resolves the archive to restore from --file, --latest or --before,
+// falling back to an interactive picker when none of them is given. Archives in the
+// configured remote storage are listed next to the local ones and downloaded into
+// backupDir when selected.
+func selectBackupFile(ctx context.Context, cmd *cobra.Command, backupDir, ext string) (string, error) {
+	file, _ := cmd.Flags().GetString("file")
+	latest, _ := cmd.Flags().GetBool("latest")
+	before, _ := cmd.Flags().GetString("before")
+
+	store, err := remoteStorageFromConfig()
+	if err != nil {
+		return "", withCode(codeConfig, fmt.Errorf("storage configuration error: %w", err))
+	}
+
+	if file != "" {
+		// یک نام ساده، نسبت به پوشه بکاپ در نظر گرفته می‌شود
+		path := file
+		if filepath.Base(file) == file {
+			path = filepath.Join(backupDir, file)
+		}
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		}
+		// اگر فایل محلی نیست، از فضای ذخیره‌سازی راه دور دریافت می‌شود
+		if store != nil && filepath.Base(file) == file {
+			if path, err := downloadArchive(ctx, store, file, backupDir); err == nil {
+				return path, nil
+			} else if ctxErr := interrupted(ctx, "download"); ctxErr != nil {
+				return "", ctxErr
+			} else if !errors.Is(err, storage.ErrNotFound) {
+				return "", withCode(codeStorage, fmt.Errorf("downloading '%s' from %s: %w", file, store, err))
+			}
+		}
+		return "", withCode(codeUsage, fmt.Errorf("backup file '%s' not found: %w", path, err))
+	}
+
+	archives, err := backup.List(backupDir, ext)
+	if err != nil && (store == nil || !os.IsNotExist(err)) {
+		return "", fmt.Errorf("error reading backup directory '%s': %w", backupDir, err)
+	}
+	if store != nil {
+		archives = mergeRemoteArchives(ctx, archives, store, ext)
+	}
+	if len(archives) == 0 {
+		return "", fmt.Errorf("no backup files found in '%s'", backupDir)
+	}
+
+	var selected backup.Archive
+	switch {
+	case latest:
+		selected, _ = backup.Latest(archives)
+	case before != "":
+		t, err := backup.ParseTime(before)
+		if err != nil {
+			return "", withCode(codeUsage, err)
+		}
+		archive, ok := backup.LatestBefore(archives, t)
+		if !ok {
+			return "", fmt.Errorf("no backup older than %s found in '%s'", t.Format(backup.TimestampLayout), backupDir)
+		}
+		selected = archive
+	default:
+		if !isInteractive() {
+			return "", withCode(codeUsage, errors.New("no backup selected and stdin is not a terminal; use --file, --latest or --before"))
+		}
+		if selected, err = pickArchive(archives); err != nil {
+			return "", withCode(codeCancelled, err)
+		}
+	}
+
+	if selected.Remote {
+		path, err := downloadArchive(ctx, store, selected.Name, backupDir)
+		if ctxErr := interrupted(ctx, "download"); ctxErr != nil {
+			return "", ctxErr
+		}
+		return path, withCode(codeStorage, err)
+	}
+	return selected.Path, nil
+}
+
+// mergeRemoteArchives adds the archives of store that are not available locally.
+// A failing remote listing only produces a warning.
+func mergeRemoteArchives(ctx context.Context, local []backup.Archive, store storage.Storage, ext string) []backup.Archive {
+	remote, err := remoteArchives(ctx, store, ext)
+	if err != nil {
+		log.Printf("Warning: could not list backups in %s: %v", store, err)
+		return local
+	}
+	names := make(map[string]bool, len(local))
+	files := append([]backup.Archive(nil), local...)
+	for _, a := range local {
+		names[a.Name] = true
+	}
+	for _, a := range remote {
+		if !names[a.Name] {
+			files = append(files, a)
+		}
+	}
+	return backup.Filter(files, ext)
+}
+
+// pickArchive lets the user choose one of archives, newest first.
+func pickArchive(archives []backup.Archive) (backup.Archive, error) {
+	options := make([]string, len(archives))
+	byLabel := make(map[string]backup.Archive, len(archives))
+	for i, archive := range archives {
+		label := describeArchive(archive)
+		options[len(archives)-1-i] = label
+		byLabel[label] = archive
+	}
+	var selected string
+	prompt := &survey.Select{
+		Message:  "Choose a backup to restore:",
+		Options:  options,
+		PageSize: 15,
+	}
+	if err := askOne(prompt, &selected, survey.WithValidator(survey.Required)); err != nil {
+		return backup.Archive{}, fmt.Errorf("no backup selected: %w", err)
+	}
+	return byLabel[selected], nil
+}
+
+// describeArchive builds the picker label of an archive from its manifest, if any.
+func describeArchive(archive backup.Archive) string {
+	if archive.Remote {
+		// manifest بکاپ‌های راه دور تا پیش از دانلود در دسترس نیست
+		return fmt.Sprintf("%s  (%s | %s | remote)", archive.Name, archive.Time.Local().Format("2006-01-02 15:04"), backup.FormatSize(archive.Size))
+	}
+	m, err := backup.ReadManifest(archive.Path)
+	if err != nil {
+		return archive.Name
+	}
+	details := []string{
+		m.StartedAt.Local().Format("2006-01-02 15:04"),
+		backup.FormatSize(m.Size),
+	}
+	if m.SourceHost != "" {
+		details = append(details, m.SourceHost)
+	}
+	if len(m.Namespaces) > 0 {
+		details = append(details, strings.Join(m.Namespaces, ","))
+	}
+	if m.Encrypted {
+		details = append(details, "encrypted")
+	}
+	if m.Oplog {
+		details = append(details, "oplog")
+	}
+	return fmt.Sprintf("%s  (%s)", archive.Name, strings.Join(details, " | "))
+}
+
+// verifyBackupFile checks the archive against its manifest checksum unless --skip-verify is set,
+// and reports whether it was verified.
+// A missing manifest (e.g. for archives created by older versions) only produces a warning.
+func verifyBackupFile(cmd *cobra.Command, archivePath string) (bool, error) {
+	if skip, _ := cmd.Flags().GetBool("skip-verify"); skip {
+		fmt.Fprintln(humanOutput, "Skipping checksum verification (--skip-verify).")
+		return false, nil
+	}
+	m, err := backup.ReadManifest(archivePath)
+	if os.IsNotExist(err) {
+		fmt.Fprintf(humanOutput, "Warning: no manifest found for '%s'; the archive cannot be verified.\n", filepath.Base(archivePath))
+		return false, nil
+	}
+	if err != nil {
+		return false, errorf(codeVerification, "failed to read backup manifest: %w", err)
+	}
+	fmt.Fprintln(humanOutput, "Verifying archive checksum...")
+	if err := backup.Verify(archivePath, m); err != nil {
+		return false, errorf(codeVerification, "backup verification failed: %w. Use --skip-verify to restore anyway", err)
+	}
+	fmt.Fprintf(humanOutput, "Checksum OK (%s).\n", m.SHA256)
+	return true, nil
+}
+
+// confirmRestore asks the user to confirm a destructive restore unless --yes was given.
+func confirmRestore(cmd *cobra.Command, message string) error {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return nil
+	}
+	if !isInteractive() {
+		return withCode(codeUsage, errors.New("refusing to restore without confirmation because stdin is not a terminal; pass --yes to proceed"))
+	}
+	confirmed := false
+	if err := askOne(&survey.Confirm{Message: message}, &confirmed); err != nil {
+		return withCode(codeCancelled, fmt.Errorf("restore cancelled: %w", err))
+	}
+	if !confirmed {
+		return withCode(codeCancelled, errors.New("restore cancelled by user"))
+	}
+	return nil
+}
+
+// isInteractive reports whether stdin is attached to a terminal.
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// addRestoreSelectionFlags registers the archive selection and confirmation flags
+// shared by all restore subcommands.
+func addRestoreSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().String("file", "", "Backup file to restore (name inside the backup directory or a path)")
+	cmd.Flags().Bool("latest", false, "Restore the most recent backup")
+	cmd.Flags().String("before", "", "Restore the newest backup created before this timestamp (e.g. 2025-06-08_14-30-00)")
+	cmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	cmd.Flags().Bool("skip-verify", false, "Do not verify the archive checksum before restoring")
+	cmd.Flags().Bool("drop", false, "Drop the existing data of the restored objects first (default: ask, or 'restore.drop')")
+	cmd.Flags().Bool("snapshot", false, "Back up the target before restoring so the restore can be undone with 'restore undo'")
+	cmd.MarkFlagsMutuallyExclusive("file", "latest", "before")
+}
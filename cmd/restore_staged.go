@@ -0,0 +1,157 @@
+// فایل: cmd/restore_staged.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+	"github.com/mshamsi502/dataweaver-cli/internal/mongodb"
+
+	"github.com/spf13/cobra"
+)
+
+// restoreMongoStaged restores an archive of backupDir into staging collections inside
+// the target databases, verifies them against the archive (document counts and
+// indexes) and only then renames them over the target collections. If anything fails
+// before the renames, the targets are left untouched and the staging collections are
+// dropped.
+func restoreMongoStaged(cmd *cobra.Command, eng *mongodb.Engine, uri, backupDir string) error {
+	ctx, cancel := operationContext(cmd)
+	defer cancel()
+	host := engine.SourceHost(uri)
+
+	// 1. انتخاب فایل بکاپ و نام‌هایی که بازیابی می‌شوند
+	path, err := selectBackupFile(ctx, cmd, backupDir, eng.Extension())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(humanOutput, "Selected backup file: %s\n", path)
+	contents := archiveContents(eng, path)
+	if len(contents) == 0 {
+		return errorf(codeVerification, "the contents of '%s' could not be read; a staged restore needs them", filepath.Base(path))
+	}
+	if _, err := eng.Remap.Resolve(contents); err != nil {
+		return withCode(codeUsage, err)
+	}
+	sources, targets := restoredPairs(eng, contents)
+	if len(targets) == 0 {
+		return errorf(codeUsage, "nothing to restore: no collection of the archive matches the namespace filters")
+	}
+	staging, err := mongodb.NewStaging(sources, targets)
+	if err != nil {
+		return withCode(codeUsage, err)
+	}
+
+	// 2. تأیید نهایی
+	fmt.Fprintf(humanOutput, "These will be restored into staging collections, verified and then replace the collections in %s:\n", host)
+	for _, ns := range targets {
+		fmt.Fprintf(humanOutput, "  %s\n", ns)
+	}
+	if err := confirmRestore(cmd, fmt.Sprintf("Restore '%s' into %s through staging collections?", filepath.Base(path), host)); err != nil {
+		return err
+	}
+
+	// 3. بررسی checksum و ابزارها
+	verified, err := verifyBackupFile(cmd, path)
+	if err != nil {
+		return err
+	}
+	if err := eng.CheckTools(); err != nil {
+		return errorf(codeToolMissing, "%w. Please verify your tools path configuration", err)
+	}
+	if _, err := eng.ShellPath(); err != nil {
+		return errorf(codeToolMissing, "a staged restore verifies and renames the collections with mongosh: %w", err)
+	}
+
+	// 4. در صورت درخواست، ابتدا از وضعیت فعلی مقصد بکاپ گرفته می‌شود
+	startedAt := time.Now()
+	var snapshot string
+	if dir := snapshotDirIfRequested(cmd, backupDir); dir != "" {
+		snapshot, err = takeSafetySnapshot(ctx, restoreRequest{Engine: eng, URI: uri, Archive: path, Namespaces: targets, SnapshotDir: dir})
+		if err != nil {
+			return err
+		}
+	}
+
+	// 5. بازیابی در کالکشن‌های staging؛ کالکشن‌های مقصد تا مرحله جابه‌جایی دست نمی‌خورند
+	stagedEng := &mongodb.Engine{ToolsPath: eng.ToolsPath, Remap: staging.Remap}
+	archive, err := openArchive(path)
+	if err != nil {
+		return errorf(codeVerification, "failed to open backup file: %w", err)
+	}
+	defer archive.Close()
+	// سندهای آرشیو همزمان با بازیابی شمرده می‌شوند
+	counter := mongodb.CountArchive(archive)
+	defer counter.Close()
+	fmt.Fprintf(humanOutput, "Executing staged mongodb restore into %s. This might take a while...\n", strings.Join(staging.Namespaces, ", "))
+	err = stagedEng.Restore(ctx, engine.RestoreOptions{URI: uri, Archive: counter, Drop: true, Output: humanOutput})
+	if ctxErr := interrupted(ctx, "restore"); ctxErr != nil {
+		return fmt.Errorf("%w%s", ctxErr, dropStaging(stagedEng, uri, staging))
+	}
+	if err != nil {
+		return errorf(codeToolFailed, "staged restore failed: %w%s", err, dropStaging(stagedEng, uri, staging))
+	}
+	expected, err := counter.Stats()
+	if err != nil {
+		return errorf(codeVerification, "failed to read the archive for verification: %w%s", err, dropStaging(stagedEng, uri, staging))
+	}
+
+	// 6. مقایسه کالکشن‌های staging با آرشیو
+	fmt.Fprintln(humanOutput, "Verifying the staged collections...")
+	actual, err := stagedEng.CollectionStats(ctx, uri, staging.Namespaces)
+	if err != nil {
+		return errorf(codeToolFailed, "failed to inspect the staged collections: %w%s", err, dropStaging(stagedEng, uri, staging))
+	}
+	report := newRestoreReport(sources, targets, statsByNamespace(expected), actual, false)
+	report.print()
+	if !report.Passed {
+		setResult(&restoreResult{Engine: eng.Name(), Archive: path, Target: host, Namespaces: targets, Staged: true, Verified: verified, Report: report})
+		return errorf(codeVerification, "the staged copy does not match the archive; %s was left untouched%s", host, dropStaging(stagedEng, uri, staging))
+	}
+
+	// 7. جابه‌جایی: هر کالکشن با renameCollection در همان پایگاه داده جایگزین می‌شود
+	fmt.Fprintln(humanOutput, "Swapping the staged collections into place...")
+	done, err := stagedEng.RenameCollections(ctx, uri, staging.Namespaces, targets)
+	if err != nil {
+		if done == 0 {
+			return errorf(codeToolFailed, "swap failed: %w; %s was left untouched%s", err, host, dropStaging(stagedEng, uri, staging))
+		}
+		return errorf(codeToolFailed, "swap failed after %d of %d collections: %w. The remaining staged collections are kept as %s%s",
+			done, len(targets), err, strings.Join(staging.Namespaces[done:], ", "), undoHint(snapshot))
+	}
+	if snapshot != "" {
+		fmt.Fprintf(humanOutput, "The previous state is kept in '%s'; undo this restore with 'dataweaver-cli restore undo'.\n", snapshot)
+	}
+
+	fmt.Fprintln(humanOutput, "------------------------")
+	fmt.Fprintln(humanOutput, "Staged restore completed successfully!")
+	setResult(&restoreResult{
+		Engine:          eng.Name(),
+		Archive:         path,
+		Target:          host,
+		Namespaces:      targets,
+		Dropped:         true,
+		Staged:          true,
+		Verified:        verified,
+		Snapshot:        snapshot,
+		Report:          report,
+		DurationSeconds: time.Since(startedAt).Round(time.Millisecond).Seconds(),
+	})
+	return nil
+}
+
+// dropStaging drops the staging collections after a failed staged restore and returns
+// the sentence appended to the error.
+func dropStaging(eng *mongodb.Engine, uri string, staging mongodb.Staging) string {
+	// context عملیات ممکن است لغو شده باشد؛ پاک‌سازی مهلت جداگانه دارد
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := eng.DropCollections(ctx, uri, staging.Namespaces); err != nil {
+		return fmt.Sprintf(". The staging collections %s could not be dropped: %v", strings.Join(staging.Namespaces, ", "), err)
+	}
+	return ". The staging collections were dropped"
+}
@@ -0,0 +1,170 @@
+// فایل: cmd/restore_undo.go
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/backup"
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+	"github.com/mshamsi502/dataweaver-cli/internal/mongodb"
+	"github.com/mshamsi502/dataweaver-cli/internal/mysql"
+	"github.com/mshamsi502/dataweaver-cli/internal/postgres"
+
+	"github.com/spf13/cobra"
+)
+
+// restoreUndoCmd restores the safety snapshot taken by 'restore --snapshot'.
+var restoreUndoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo a restore by restoring the safety snapshot taken before it",
+	Long: `Restores the newest safety snapshot taken by 'restore <engine> --snapshot' (or
+'restore.snapshot: true') back into the database it was taken from. The snapshots
+are kept in the 'pre-restore' subdirectory of 'paths.backup'.
+
+The existing data of the snapshotted objects is dropped first. Objects that only
+the undone restore created are not removed.
+
+Use --list to show the available snapshots and --file to pick an older one.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		backupDir := config.GetString("paths.backup")
+		if backupDir == "" {
+			return errorf(codeConfig, "configuration error: 'paths.backup' must be set")
+		}
+		dir := filepath.Join(backupDir, safetySnapshotDir)
+
+		// 1. فهرست safety snapshotهای همه انجین‌ها
+		snapshots, err := listSafetySnapshots(dir)
+		if err != nil {
+			return fmt.Errorf("error reading snapshot directory '%s': %w", dir, err)
+		}
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			if len(snapshots) == 0 {
+				fmt.Fprintf(humanOutput, "No safety snapshots found in '%s'.\n", dir)
+			}
+			for i := len(snapshots) - 1; i >= 0; i-- {
+				fmt.Fprintln(humanOutput, describeSafetySnapshot(snapshots[i]))
+			}
+			return nil
+		}
+
+		// 2. انتخاب snapshot (جدیدترین به صورت پیش‌فرض)
+		var selected *backup.Archive
+		if file, _ := cmd.Flags().GetString("file"); file != "" {
+			for i := range snapshots {
+				if snapshots[i].Name == filepath.Base(file) {
+					selected = &snapshots[i]
+				}
+			}
+			if selected == nil {
+				return errorf(codeUsage, "safety snapshot '%s' not found in '%s'", file, dir)
+			}
+		} else if len(snapshots) > 0 {
+			selected = &snapshots[len(snapshots)-1]
+		} else {
+			return fmt.Errorf("no safety snapshots found in '%s'; restores only take one with --snapshot", dir)
+		}
+		fmt.Fprintf(humanOutput, "Selected safety snapshot: %s\n", describeSafetySnapshot(*selected))
+
+		// 3. snapshot فقط به همان سروری برگردانده می‌شود که از آن گرفته شده است
+		m, err := backup.ReadManifest(selected.Path)
+		if err != nil {
+			return errorf(codeVerification, "failed to read the manifest of '%s': %w", selected.Name, err)
+		}
+		eng, uri, err := restoreTarget(m.Engine)
+		if err != nil {
+			return err
+		}
+		if host := engine.SourceHost(uri); m.SourceHost != "" && host != m.SourceHost {
+			return errorf(codeConfig, "the snapshot was taken from %s but the configured target is %s; select the matching --profile", m.SourceHost, host)
+		}
+
+		// 4. بازگرداندن snapshot با حذف داده‌های فعلی
+		ctx, cancel := operationContext(cmd)
+		defer cancel()
+		result, err := restoreArchive(ctx, cmd, restoreRequest{
+			Engine:     eng,
+			URI:        uri,
+			Archive:    selected.Path,
+			Namespaces: describeRestore(eng, selected.Path),
+			Drop:       true,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(humanOutput, "------------------------")
+		fmt.Fprintln(humanOutput, "Restore undone successfully!")
+		setResult(result)
+		return nil
+	},
+}
+
+// listSafetySnapshots returns the safety snapshots of every engine in dir, oldest
+// first. A missing directory holds no snapshots.
+func listSafetySnapshots(dir string) ([]backup.Archive, error) {
+	var snapshots []backup.Archive
+	for _, eng := range registeredEngines {
+		archives, err := backup.List(dir, eng.Extension())
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, archives...)
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.Before(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// describeSafetySnapshot adds the restored archive to the label of a snapshot.
+func describeSafetySnapshot(archive backup.Archive) string {
+	label := describeArchive(archive)
+	if m, err := backup.ReadManifest(archive.Path); err == nil && m.SafetySnapshotFor != "" {
+		label += fmt.Sprintf(", taken before restoring %s", m.SafetySnapshotFor)
+	}
+	return label
+}
+
+// restoreTarget returns the engine and the configured restore target of the named
+// engine (see engine.Engine.Name).
+func restoreTarget(name string) (engine.Engine, string, error) {
+	var eng engine.Engine
+	var key string
+	switch name {
+	case "mongodb":
+		toolsPath, err := findMongoTools()
+		if err != nil {
+			return nil, "", err
+		}
+		eng, key = &mongodb.Engine{ToolsPath: toolsPath}, "mongodb.local_uri"
+	case "postgres":
+		eng, key = &postgres.Engine{ToolsPath: config.GetString("paths.postgres_tools")}, "postgres.local_uri"
+	case "mysql":
+		eng, key = &mysql.Engine{ToolsPath: config.GetString("paths.mysql_tools")}, "mysql.local_uri"
+	default:
+		return nil, "", errorf(codeConfig, "unknown engine %q in the snapshot manifest", name)
+	}
+	uri, err := uriFromConfig(key)
+	if err != nil {
+		return nil, "", err
+	}
+	if uri == "" {
+		return nil, "", errorf(codeConfig, "configuration error: '%s' must be set", key)
+	}
+	return eng, uri, nil
+}
+
+func init() {
+	restoreCmd.AddCommand(restoreUndoCmd)
+	restoreUndoCmd.Flags().String("file", "", "Safety snapshot to restore (default: the newest)")
+	restoreUndoCmd.Flags().Bool("list", false, "List the safety snapshots instead of restoring one")
+	restoreUndoCmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	restoreUndoCmd.Flags().Bool("skip-verify", false, "Do not verify the snapshot checksum before restoring")
+	addTimeoutFlag(restoreUndoCmd)
+}
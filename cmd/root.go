@@ -2,37 +2,70 @@ package cmd
 
 import (
 	"fmt"
+	"log"
 	"os"
 
 	"github.com/mshamsi502/dataweaver-cli/internal/config" // مسیر پکیج کانفیگ
+	"github.com/mshamsi502/dataweaver-cli/internal/redact"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/spf13/cobra"
 )
 
+// Version is the CLI version, set at build time with
+// -ldflags "-X github.com/mshamsi502/dataweaver-cli/cmd.Version=v1.2.3".
+var Version = "dev"
+
 var rootCmd = &cobra.Command{
-	Use:   "dataweaver-cli",
-	Short: "A CLI tool for managing database operations",
-	Long:  `DataWeaver CLI is a powerful, self-contained tool to handle backup, restore, and other database operations.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	Use:     "dataweaver-cli",
+	Version: Version,
+	Short:   "A CLI tool for managing database operations",
+	Long:    `DataWeaver CLI is a powerful, self-contained tool to handle backup, restore, and other database operations.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// فلگ‌ها معتبرند؛ خطاهای بعدی نیازی به نمایش راهنمای دستور ندارند
+		cmd.SilenceUsage = true
+		if err := setupOutput(cmd); err != nil {
+			return err
+		}
+		// انتخاب پروفایل از طریق فلگ سراسری --profile
+		code := codeConfig
+		if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+			config.SetActiveProfile(profile)
+			code = codeUsage
+		}
+		return withCode(code, config.ValidateActiveProfile())
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if jsonOutput {
+			return errorf(codeUsage, "the interactive menu has no JSON output; run a command such as 'backup mongo' instead")
+		}
 		// به جای ارجاع به متغیر سراسری rootCmd، خود cmd را به تابع پاس می‌دهیم
 		runInteractiveMenu(cmd)
+		return nil
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		printResult()
 	},
 }
 
 // تابع اصلی برای اجرای منوی تعاملی
 func runInteractiveMenu(cmd *cobra.Command) {
-	fmt.Println("Welcome to DataWeaver CLI! (Press Ctrl+C to exit at any time)")
+	fmt.Fprintln(humanOutput, "Welcome to DataWeaver CLI! (Press Ctrl+C to exit at any time)")
 
 	// بارگذاری اولیه کانفیگ
 	if err := config.LoadConfig(); err != nil {
-		fmt.Printf("Notice: Could not load config file. Use 'Configure' to create one. (%v)\n", err)
+		fmt.Fprintf(humanOutput, "Notice: Could not load config file. Use 'Configure' to create one. (%v)\n", err)
 	}
 
 	// **تغییر اصلی اینجاست:**
 	// ما از متغیر 'cmd' که به تابع پاس داده شده استفاده می‌کنیم، نه از 'rootCmd' سراسری.
 	backupMongo, _, _ := cmd.Find([]string{"backup", "mongo"})
 	restoreMongo, _, _ := cmd.Find([]string{"restore", "mongo"})
+	cloneMongo, _, _ := cmd.Find([]string{"clone", "mongo"})
+	backupPostgres, _, _ := cmd.Find([]string{"backup", "postgres"})
+	restorePostgres, _, _ := cmd.Find([]string{"restore", "postgres"})
+	backupMySQL, _, _ := cmd.Find([]string{"backup", "mysql"})
+	restoreMySQL, _, _ := cmd.Find([]string{"restore", "mysql"})
 	configure, _, _ := cmd.Find([]string{"configure"})
 	downloadTools, _, _ := cmd.Find([]string{"download-tools"})
 	configurePath, _, _ := cmd.Find([]string{"configure", "path"})
@@ -42,6 +75,11 @@ func runInteractiveMenu(cmd *cobra.Command) {
 		options := []string{
 			"Backup MongoDB",
 			"Restore MongoDB",
+			"Clone MongoDB (Remote -> Local)",
+			"Backup PostgreSQL",
+			"Restore PostgreSQL",
+			"Backup MySQL",
+			"Restore MySQL",
 			"---", // جداکننده برای زیبایی
 			"Configure Settings (Interactive)",
 			"Download/Setup Tools",
@@ -55,63 +93,98 @@ func runInteractiveMenu(cmd *cobra.Command) {
 		prompt := &survey.Select{
 			Message:  "Choose an option:",
 			Options:  options,
-			PageSize: 10,
+			PageSize: 15,
 		}
-		err := survey.AskOne(prompt, &selectedOption, survey.WithStdio(os.Stdin, os.Stderr, os.Stdout))
+		err := askOne(prompt, &selectedOption, survey.WithStdio(os.Stdin, os.Stderr, os.Stdout))
 		if err != nil {
 			return // خروج در صورت فشردن Ctrl+C
 		}
 
-		fmt.Println() // یک خط خالی برای فاصله
+		fmt.Fprintln(humanOutput) // یک خط خالی برای فاصله
 
 		switch selectedOption {
 		case "Backup MongoDB":
-			fmt.Println("--- Running Backup ---")
-			// اجرای دستور پیدا شده
-			if backupMongo != nil {
-				backupMongo.Run(backupMongo, []string{})
+			fmt.Fprintln(humanOutput, "--- Running Backup ---")
+			if !chooseProfileInteractive() {
+				continue
 			}
-			fmt.Println("--- Backup Finished ---\n")
+			// اجرای دستور پیدا شده
+			runMenuCommand(backupMongo)
+			fmt.Fprint(humanOutput, "--- Backup Finished ---\n\n")
 
 		case "Restore MongoDB":
-			fmt.Println("\n--- Running Restore ---")
-			if restoreMongo != nil {
-				restoreMongo.Run(restoreMongo, []string{})
+			fmt.Fprintln(humanOutput, "\n--- Running Restore ---")
+			if !chooseProfileInteractive() {
+				continue
+			}
+			runMenuCommand(restoreMongo)
+			fmt.Fprint(humanOutput, "--- Restore Finished ---\n\n")
+
+		case "Clone MongoDB (Remote -> Local)":
+			fmt.Fprintln(humanOutput, "\n--- Running Clone ---")
+			if !chooseProfileInteractive() {
+				continue
 			}
-			fmt.Println("--- Restore Finished ---\n")
+			runMenuCommand(cloneMongo)
+			fmt.Fprint(humanOutput, "--- Clone Finished ---\n\n")
+
+		case "Backup PostgreSQL":
+			fmt.Fprintln(humanOutput, "--- Running Backup ---")
+			if !chooseProfileInteractive() {
+				continue
+			}
+			runMenuCommand(backupPostgres)
+			fmt.Fprint(humanOutput, "--- Backup Finished ---\n\n")
+
+		case "Restore PostgreSQL":
+			fmt.Fprintln(humanOutput, "\n--- Running Restore ---")
+			if !chooseProfileInteractive() {
+				continue
+			}
+			runMenuCommand(restorePostgres)
+			fmt.Fprint(humanOutput, "--- Restore Finished ---\n\n")
+
+		case "Backup MySQL":
+			fmt.Fprintln(humanOutput, "--- Running Backup ---")
+			if !chooseProfileInteractive() {
+				continue
+			}
+			runMenuCommand(backupMySQL)
+			fmt.Fprint(humanOutput, "--- Backup Finished ---\n\n")
+
+		case "Restore MySQL":
+			fmt.Fprintln(humanOutput, "\n--- Running Restore ---")
+			if !chooseProfileInteractive() {
+				continue
+			}
+			runMenuCommand(restoreMySQL)
+			fmt.Fprint(humanOutput, "--- Restore Finished ---\n\n")
 
 		case "Configure Settings (Interactive)":
-			fmt.Println("\n--- Running Interactive Configuration ---")
+			fmt.Fprintln(humanOutput, "\n--- Running Interactive Configuration ---")
 			if configure != nil {
 				// تابع Run دستور configure را برای حالت تعاملی فراخوانی می‌کنیم
-				runConfiguration(configure)
+				showMenuError(runConfiguration(configure))
 			}
-			fmt.Println("--- Configuration Finished ---\n")
+			fmt.Fprint(humanOutput, "--- Configuration Finished ---\n\n")
 
 		case "Edit Config File":
-			fmt.Println("\n--- Opening Config File ---")
-			if configureEdit != nil {
-				// تابع Run دستور 'configure edit' را فراخوانی می‌کنیم
-				configureEdit.Run(configureEdit, []string{})
-			}
-			fmt.Println("--- Action Finished ---\n")
+			fmt.Fprintln(humanOutput, "\n--- Opening Config File ---")
+			runMenuCommand(configureEdit)
+			fmt.Fprint(humanOutput, "--- Action Finished ---\n\n")
 
 		case "Show Config Path":
-			fmt.Println("\n--- Config File Path ---")
-			if configurePath != nil {
-				configurePath.Run(configurePath, []string{})
-			}
-			fmt.Println("--- Done ---\n")
+			fmt.Fprintln(humanOutput, "\n--- Config File Path ---")
+			runMenuCommand(configurePath)
+			fmt.Fprint(humanOutput, "--- Done ---\n\n")
 
 		case "Download/Setup Tools":
-			fmt.Println("\n--- Running Download/Setup Tools ---")
-			if downloadTools != nil {
-				downloadTools.Run(downloadTools, []string{})
-			}
-			fmt.Println("--- Download/Setup Finished ---\n")
+			fmt.Fprintln(humanOutput, "\n--- Running Download/Setup Tools ---")
+			runMenuCommand(downloadTools)
+			fmt.Fprint(humanOutput, "--- Download/Setup Finished ---\n\n")
 
 		case "Exit":
-			fmt.Println("Exiting. Goodbye!")
+			fmt.Fprintln(humanOutput, "Exiting. Goodbye!")
 			return
 
 		case "---":
@@ -119,17 +192,77 @@ func runInteractiveMenu(cmd *cobra.Command) {
 			continue
 		}
 
-		fmt.Println("\nPress Enter to return to the main menu...")
+		fmt.Fprintln(humanOutput, "\nPress Enter to return to the main menu...")
 		fmt.Scanln()
 	}
 }
 
+// chooseProfileInteractive lets the user pick a connection profile when profiles are configured.
+// It returns false if the prompt was cancelled.
+func chooseProfileInteractive() bool {
+	names := config.ProfileNames()
+	if len(names) == 0 {
+		return true
+	}
+	selected := config.ActiveProfile()
+	if selected == "" {
+		selected = names[0]
+	}
+	prompt := &survey.Select{
+		Message: "Choose a profile:",
+		Options: names,
+		Default: selected,
+	}
+	if err := askOne(prompt, &selected); err != nil {
+		return false
+	}
+	config.SetActiveProfile(selected)
+	return true
+}
+
+// runMenuCommand runs a command chosen in the interactive menu. A failing command
+// does not end the menu; its error is shown instead.
+func runMenuCommand(c *cobra.Command) {
+	if c != nil {
+		showMenuError(c.RunE(c, []string{}))
+	}
+}
+
+func showMenuError(err error) {
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
+
+// Execute runs the command given on the command line. A failing command prints its
+// error (see reportError) and exits with the status of its error code (see exitCodes).
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	err := rootCmd.Execute()
+	if err == nil {
+		return
 	}
+	// خطاهای cobra (فلگ یا آرگومان نامعتبر) پیش از اجرای دستور رخ می‌دهند و کد ندارند
+	if commandStartedAt.IsZero() && errorCode(err) == codeFailed {
+		err = withCode(codeUsage, err)
+	}
+	if jsonOutputRequested(os.Args[1:]) {
+		jsonOutput = true
+	}
+	reportError(err)
+	os.Exit(exitCode(err))
 }
 
 func init() {
-	// این تابع خالی است. ثبت دستورات در فایل‌های خودشان انجام می‌شود.
+	// ثبت دستورات در فایل‌های خودشان انجام می‌شود.
+	// اینجا فقط فایل کانفیگ را برای اجرای مستقیم دستورات (بدون منوی تعاملی) بارگذاری می‌کنیم.
+	cobra.OnInitialize(setupViperConfigPaths)
+
+	// رمزهای داخل URIها نباید در خروجی یا لاگ‌ها دیده شوند
+	log.SetOutput(redact.NewWriter(os.Stderr))
+	rootCmd.SetErr(redact.NewWriter(os.Stderr))
+	// خطاها توسط Execute همراه با کد خروج گزارش می‌شوند
+	rootCmd.SilenceErrors = true
+
+	rootCmd.PersistentFlags().String("profile", "", "Connection profile to use (default: 'default_profile' from the config file)")
+	rootCmd.PersistentFlags().StringP("output", "o", "text", "Output format: text, or json for a machine-readable result on stdout")
 }
@@ -0,0 +1,156 @@
+// فایل: cmd/storage.go
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/backup"
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// remoteStorageFromConfig builds the remote storage of the active profile from the
+// 'storage.*' keys. It returns nil if no storage is configured.
+func remoteStorageFromConfig() (storage.Storage, error) {
+	storageType := config.GetString("storage.type")
+	if storageType == "" {
+		return nil, nil
+	}
+	secretKey, err := config.GetSecret("storage.secret_key")
+	if err != nil {
+		return nil, err
+	}
+	password, err := config.GetSecret("storage.password")
+	if err != nil {
+		return nil, err
+	}
+	return storage.New(storage.Config{
+		Type:                  storageType,
+		Path:                  config.GetString("storage.path"),
+		Endpoint:              config.GetString("storage.endpoint"),
+		Bucket:                config.GetString("storage.bucket"),
+		Prefix:                config.GetString("storage.prefix"),
+		Region:                config.GetString("storage.region"),
+		AccessKey:             config.GetString("storage.access_key"),
+		SecretKey:             secretKey,
+		PathStyle:             config.GetBool("storage.path_style"),
+		Host:                  config.GetString("storage.host"),
+		Port:                  config.GetInt("storage.port"),
+		KeyFile:               config.ExpandHome(config.GetString("storage.key_file")),
+		KnownHosts:            config.ExpandHome(config.GetString("storage.known_hosts")),
+		InsecureIgnoreHostKey: config.GetBool("storage.insecure_ignore_host_key"),
+		URL:                   config.GetString("storage.url"),
+		User:                  config.GetString("storage.user"),
+		Password:              password,
+	})
+}
+
+// uploadBackupIfRequested uploads a finished archive and its manifest when --upload
+// (or 'storage.upload') is set, and returns the storage location it was uploaded to.
+func uploadBackupIfRequested(ctx context.Context, cmd *cobra.Command, archivePath string) (string, error) {
+	upload := config.GetBool("storage.upload")
+	if cmd.Flags().Changed("upload") {
+		upload, _ = cmd.Flags().GetBool("upload")
+	}
+	if !upload {
+		return "", nil
+	}
+	store, err := remoteStorageFromConfig()
+	if err != nil {
+		return "", withCode(codeConfig, fmt.Errorf("storage configuration error: %w", err))
+	}
+	if store == nil {
+		return "", withCode(codeConfig, errors.New("configuration error: --upload needs a remote storage ('storage.type'). See the README for examples"))
+	}
+
+	fmt.Fprintf(humanOutput, "Uploading backup to %s...\n", store)
+	// ابتدا آرشیو و سپس manifest، تا manifest فقط برای آرشیو کامل وجود داشته باشد
+	for _, path := range []string{archivePath, backup.ManifestPath(archivePath)} {
+		if err := uploadFile(ctx, store, path); err != nil {
+			if ctxErr := interrupted(ctx, "upload"); ctxErr != nil {
+				return "", fmt.Errorf("%w. The local copy is kept in '%s'", ctxErr, filepath.Dir(archivePath))
+			}
+			return "", withCode(codeStorage, fmt.Errorf("upload of '%s' failed: %w. The local copy is kept in '%s'", filepath.Base(path), err, filepath.Dir(archivePath)))
+		}
+	}
+	fmt.Fprintln(humanOutput, "Upload completed successfully!")
+	return store.String(), nil
+}
+
+func uploadFile(ctx context.Context, store storage.Storage, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return store.Put(ctx, filepath.Base(path), f, info.Size())
+}
+
+// remoteArchives lists the archives with the given extension in store.
+func remoteArchives(ctx context.Context, store storage.Storage, ext string) ([]backup.Archive, error) {
+	objects, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]backup.Archive, len(objects))
+	for i, o := range objects {
+		files[i] = backup.Archive{Name: o.Name, Time: o.ModTime, Size: o.Size, Remote: true}
+	}
+	return backup.Filter(files, ext), nil
+}
+
+// downloadArchive copies a remote archive and its manifest (if any) into backupDir and
+// returns the local archive path.
+func downloadArchive(ctx context.Context, store storage.Storage, name, backupDir string) (string, error) {
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return "", err
+	}
+	fmt.Fprintf(humanOutput, "Downloading '%s' from %s...\n", name, store)
+	archivePath := filepath.Join(backupDir, name)
+	if err := downloadFile(ctx, store, name, archivePath); err != nil {
+		return "", err
+	}
+	manifestPath := backup.ManifestPath(archivePath)
+	if err := downloadFile(ctx, store, filepath.Base(manifestPath), manifestPath); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return "", err
+	}
+	return archivePath, nil
+}
+
+func downloadFile(ctx context.Context, store storage.Storage, name, path string) error {
+	r, err := store.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	// نوشتن در فایل موقت و سپس تغییر نام، تا فایل ناقص به عنوان بکاپ دیده نشود
+	f, err := os.Create(path + backup.PartialSuffix)
+	if err != nil {
+		return err
+	}
+	if _, err := f.ReadFrom(r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// addUploadFlag registers --upload on a backup subcommand.
+func addUploadFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("upload", false, "Upload the archive and its manifest to the configured remote storage (default: 'storage.upload')")
+}
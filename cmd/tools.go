@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/downloader"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	toolsDownloadDir = "./downloads/"
+	toolsInstallDir  = "./tools/" // پوشه‌ای که ابزارها در آن استخراج می‌شوند
+)
+
+var toolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "Manage installed versions of the MongoDB Database Tools.",
+	Long: `Lists, installs and switches between versions of the MongoDB Database Tools.
+
+Versions are installed side by side under ./tools, so an older mongodump can be kept
+for an older server. 'tools use' points 'paths.mongo_tools' at one of them.
+
+The known versions come from a catalog built into the CLI. Add versions or pin
+checksums in ~/.dataweaver-cli/tools-catalog.json (or the file set in 'tools.catalog').`,
+}
+
+var toolsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the available and installed tool versions.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		catalog, err := loadToolsCatalog()
+		if err != nil {
+			return err
+		}
+		installed, err := downloader.InstalledMongoTools(toolsInstallDir)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", toolsInstallDir, err)
+		}
+		active := activeMongoToolsVersion(installed)
+
+		fmt.Fprintln(humanOutput, "MongoDB Database Tools:")
+		var versions []toolVersionInfo
+		seen := make(map[string]bool)
+		for _, v := range catalog.MongoTools.Versions {
+			seen[v.Version] = true
+			versions = append(versions, toolVersionInfo{v.Version, v.Version == catalog.MongoTools.Default, installed[v.Version] != "", v.Version == active, v.Notes})
+		}
+		// نسخه‌هایی که نصب شده‌اند اما در کاتالوگ نیستند
+		for version := range installed {
+			if !seen[version] {
+				versions = append(versions, toolVersionInfo{version, false, true, version == active, "not in catalog"})
+			}
+		}
+		for _, v := range versions {
+			printToolVersion(v)
+		}
+		setResult(versions)
+		return nil
+	},
+}
+
+// toolVersionInfo describes a tools version in 'tools list'.
+type toolVersionInfo struct {
+	Version   string `json:"version"`
+	Default   bool   `json:"default"`
+	Installed bool   `json:"installed"`
+	Active    bool   `json:"active"`
+	Notes     string `json:"notes,omitempty"`
+}
+
+var toolsInstallCmd = &cobra.Command{
+	Use:   "install [version]",
+	Short: "Download and install a version of the MongoDB Database Tools.",
+	Long: `Downloads, verifies and extracts a version of the MongoDB Database Tools into
+./tools. Without a version the catalog default is installed. Pass --use to switch
+'paths.mongo_tools' to it; the first version installed is always activated.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		catalog, err := loadToolsCatalog()
+		if err != nil {
+			return err
+		}
+		version := catalog.MongoTools.Default
+		if len(args) == 1 {
+			version = args[0]
+		}
+		if _, ok := catalog.MongoTools.Find(version); !ok {
+			fmt.Fprintf(humanOutput, "Warning: version %s is not in the tools catalog.\n", version)
+		}
+
+		installed, err := downloader.InstalledMongoTools(toolsInstallDir)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", toolsInstallDir, err)
+		}
+		binPath, err := installMongoTools(cmd, catalog, version)
+		if err != nil {
+			return err
+		}
+
+		use, _ := cmd.Flags().GetBool("use")
+		activate := use || activeMongoToolsVersion(installed) == ""
+		if activate {
+			updateMongoToolsPathInConfig(binPath)
+		} else {
+			fmt.Fprintf(humanOutput, "Installed %s. Run 'dataweaver-cli tools use %s' to switch to it.\n", version, version)
+		}
+		setResult(&toolsInstallResult{Version: version, BinDir: binPath, Active: activate})
+		return nil
+	},
+}
+
+var toolsUseCmd = &cobra.Command{
+	Use:   "use <version>",
+	Short: "Switch paths.mongo_tools to an installed version.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version := args[0]
+		installed, err := downloader.InstalledMongoTools(toolsInstallDir)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", toolsInstallDir, err)
+		}
+		dir, ok := installed[version]
+		if !ok {
+			return errorf(codeUsage, "version %s is not installed. Run 'dataweaver-cli tools install %s' first", version, version)
+		}
+		binPath, err := downloader.FindBinDir(dir, "mongodump")
+		if err != nil {
+			return fmt.Errorf("failed to find tools: %w", err)
+		}
+		updateMongoToolsPathInConfig(binPath)
+		setResult(&toolsInstallResult{Version: version, BinDir: binPath, Active: true})
+		return nil
+	},
+}
+
+var toolsUninstallCmd = &cobra.Command{
+	Use:   "uninstall <version>",
+	Short: "Remove an installed version.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version := args[0]
+		installed, err := downloader.InstalledMongoTools(toolsInstallDir)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", toolsInstallDir, err)
+		}
+		dir, ok := installed[version]
+		if !ok {
+			return errorf(codeUsage, "version %s is not installed", version)
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		if activeMongoToolsVersion(installed) == version && !force {
+			return errorf(codeUsage, "version %s is in use ('paths.mongo_tools'). Switch with 'tools use' first, or pass --force", version)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("error removing %s: %w", dir, err)
+		}
+		fmt.Fprintf(humanOutput, "Removed %s\n", dir)
+		setResult(map[string]string{"version": version, "removed": dir})
+		return nil
+	},
+}
+
+// toolsInstallResult is the outcome of 'tools install', 'tools use' and 'download-tools'.
+type toolsInstallResult struct {
+	Version string `json:"version"`
+	BinDir  string `json:"bin_dir"`
+	Active  bool   `json:"active"`
+}
+
+func printToolVersion(v toolVersionInfo) {
+	marker := " "
+	if v.Active {
+		marker = "*"
+	}
+	var tags []string
+	if v.Default {
+		tags = append(tags, "default")
+	}
+	if v.Installed {
+		tags = append(tags, "installed")
+	}
+	if v.Notes != "" {
+		tags = append(tags, v.Notes)
+	}
+	line := fmt.Sprintf("%s %s", marker, v.Version)
+	if len(tags) > 0 {
+		line += fmt.Sprintf(" (%s)", strings.Join(tags, ", "))
+	}
+	fmt.Fprintln(humanOutput, line)
+}
+
+// loadToolsCatalog returns the built-in tools catalog merged with the user's override file.
+func loadToolsCatalog() (*downloader.Catalog, error) {
+	overridePath := viper.GetString("tools.catalog")
+	if overridePath == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			overridePath = filepath.Join(home, ".dataweaver-cli", "tools-catalog.json")
+		}
+	}
+	catalog, err := downloader.LoadCatalog(overridePath)
+	if err != nil {
+		return nil, errorf(codeConfig, "error loading tools catalog: %w", err)
+	}
+	return catalog, nil
+}
+
+// activeMongoToolsVersion returns the installed version 'paths.mongo_tools' points into, or "".
+func activeMongoToolsVersion(installed map[string]string) string {
+	toolsPath := viper.GetString("paths.mongo_tools")
+	if toolsPath == "" {
+		return ""
+	}
+	toolsPath, _ = filepath.Abs(toolsPath)
+	for version, dir := range installed {
+		absDir, _ := filepath.Abs(dir)
+		if toolsPath == absDir || strings.HasPrefix(toolsPath, absDir+string(filepath.Separator)) {
+			return version
+		}
+	}
+	return ""
+}
+
+// installMongoTools downloads, verifies and extracts a tools version into ./tools and
+// returns the directory holding its executables.
+func installMongoTools(cmd *cobra.Command, catalog *downloader.Catalog, version string) (string, error) {
+	fmt.Fprintf(humanOutput, "Installing MongoDB Database Tools %s...\n", version)
+
+	// --- 1. تعیین URL و مسیرهای لازم ---
+	platform, _ := cmd.Flags().GetString("platform")
+	directDownloadURL, err := downloader.MongoToolsURL(version, runtime.GOOS, runtime.GOARCH, platform)
+	if err != nil {
+		return "", withCode(codeUsage, err)
+	}
+	fmt.Fprintf(humanOutput, "Using archive: %s\n", directDownloadURL)
+
+	fileName := filepath.Base(directDownloadURL)
+	downloadFilePath := filepath.Join(toolsDownloadDir, fileName)
+
+	// --- 2. تعیین checksum مورد انتظار ---
+	skipVerify, _ := cmd.Flags().GetBool("insecure-skip-verify")
+	expectedSHA256, checksumSource, err := expectedToolsChecksum(cmd, catalog, directDownloadURL)
+	if err != nil {
+		return "", err
+	}
+	if expectedSHA256 == "" && !skipVerify {
+		return "", errorf(codeVerification, "no checksum available for '%s'. Pass the expected value with --sha256, or --insecure-skip-verify to install without verification", fileName)
+	}
+	if expectedSHA256 != "" {
+		fmt.Fprintf(humanOutput, "Expected SHA-256 (%s): %s\n", checksumSource, expectedSHA256)
+	}
+
+	// --- 3. دانلود فایل (اگر وجود ندارد یا سالم نیست) ---
+	fmt.Fprintf(humanOutput, "Checking for installer at: %s\n", downloadFilePath)
+	if err := os.MkdirAll(toolsDownloadDir, 0755); err != nil {
+		return "", fmt.Errorf("error creating download directory %s: %w", toolsDownloadDir, err)
+	}
+	// باقی‌مانده دانلودهای نیمه‌کاره قبلی
+	os.Remove(downloadFilePath + ".part")
+
+	needsDownload := true
+	if _, err := os.Stat(downloadFilePath); err == nil {
+		needsDownload = false
+		if expectedSHA256 != "" {
+			if err := downloader.VerifyFile(downloadFilePath, expectedSHA256); err != nil {
+				fmt.Fprintf(humanOutput, "Existing installer is corrupt or incomplete (%v). Downloading again...\n", err)
+				os.Remove(downloadFilePath)
+				needsDownload = true
+			}
+		}
+		if !needsDownload {
+			fmt.Fprintln(humanOutput, "Installer archive already exists. Skipping download.")
+		}
+	}
+	if needsDownload {
+		fmt.Fprintln(humanOutput, "Downloading...")
+		size, err := downloader.DownloadFile(directDownloadURL, downloadFilePath)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(humanOutput, "Successfully downloaded '%s' (%d bytes).\n", fileName, size)
+	}
+
+	// --- 4. بررسی checksum قبل از استخراج ---
+	if expectedSHA256 != "" {
+		if err := downloader.VerifyFile(downloadFilePath, expectedSHA256); err != nil {
+			os.Remove(downloadFilePath)
+			return "", errorf(codeVerification, "refusing to extract: %w. The file has been deleted", err)
+		}
+		fmt.Fprintln(humanOutput, "Checksum verified.")
+	} else {
+		fmt.Fprintln(humanOutput, "WARNING: installing without checksum verification (--insecure-skip-verify).")
+	}
+
+	// --- 5. استخراج فایل و پیدا کردن پوشه bin ---
+	fmt.Fprintf(humanOutput, "Extracting '%s' to '%s'...\n", downloadFilePath, toolsInstallDir)
+	if err := downloader.Extract(downloadFilePath, toolsInstallDir); err != nil {
+		return "", fmt.Errorf("failed to extract tools: %w", err)
+	}
+	archiveRoot := filepath.Join(toolsInstallDir, downloader.MongoToolsDirName(directDownloadURL))
+	binPath, err := downloader.FindBinDir(archiveRoot, "mongodump")
+	if err != nil {
+		return "", fmt.Errorf("failed to find tools: %w", err)
+	}
+	fmt.Fprintln(humanOutput, "Extraction complete.")
+	return binPath, nil
+}
+
+// expectedToolsChecksum returns the SHA-256 the archive at url must have, and where it
+// came from: the checksum pinned in the catalog, the --sha256 flag, or the checksum
+// published by MongoDB. A pinned checksum is authoritative; --sha256 may not contradict
+// it and the release feed is only consulted for archives that are not pinned.
+func expectedToolsChecksum(cmd *cobra.Command, catalog *downloader.Catalog, url string) (string, string, error) {
+	flagSum, _ := cmd.Flags().GetString("sha256")
+	flagSum = strings.ToLower(strings.TrimSpace(flagSum))
+	if sum := catalog.MongoTools.PinnedSHA256(filepath.Base(url)); sum != "" {
+		if flagSum != "" && flagSum != sum {
+			return "", "", errorf(codeVerification, "--sha256 %s does not match the checksum pinned in the tools catalog for '%s' (%s)", flagSum, filepath.Base(url), sum)
+		}
+		return sum, "catalog", nil
+	}
+	if flagSum != "" {
+		return flagSum, "--sha256", nil
+	}
+	sum, err := downloader.PublishedMongoToolsSHA256(url)
+	if err != nil {
+		fmt.Fprintf(humanOutput, "Could not get the published checksum: %v\n", err)
+		return "", "", nil
+	}
+	return sum, "published by MongoDB", nil
+}
+
+// addToolsInstallFlags registers the flags shared by 'tools install' and 'download-tools'.
+func addToolsInstallFlags(cmd *cobra.Command) {
+	cmd.Flags().String("platform", "", "MongoDB platform name, e.g. ubuntu2204-x86_64 (default: detected)")
+	cmd.Flags().String("sha256", "", "Expected SHA-256 of the archive, for versions not pinned in the catalog (overrides the published checksum)")
+	cmd.Flags().Bool("insecure-skip-verify", false, "Install even if no checksum is available to verify the archive")
+}
+
+func init() {
+	rootCmd.AddCommand(toolsCmd)
+	toolsCmd.AddCommand(toolsListCmd)
+	toolsCmd.AddCommand(toolsInstallCmd)
+	toolsCmd.AddCommand(toolsUseCmd)
+	toolsCmd.AddCommand(toolsUninstallCmd)
+
+	addToolsInstallFlags(toolsInstallCmd)
+	toolsInstallCmd.Flags().Bool("use", false, "Switch paths.mongo_tools to the installed version")
+	toolsUninstallCmd.Flags().Bool("force", false, "Remove the version even if it is in use")
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/config"
+	"github.com/mshamsi502/dataweaver-cli/internal/downloader"
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+	"github.com/mshamsi502/dataweaver-cli/internal/mongodb"
+
+	"github.com/spf13/cobra"
+)
+
+var toolsDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Show where the MongoDB Database Tools are looked up and which ones are used.",
+	Long: `Prints every location checked for mongodump and mongorestore, in order:
+the configured 'paths.mongo_tools', PATH, the well-known install locations of the
+package managers, and the versions installed under ./tools. The first location that
+has both tools is used by backup, restore and clone.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, chain, err := mongodb.ResolveTools(config.GetString("paths.mongo_tools"), localMongoToolDirs())
+
+		fmt.Fprintln(humanOutput, "MongoDB Database Tools resolution:")
+		picked := false
+		for i, c := range chain {
+			status := "ok"
+			if c.Err != nil {
+				status = c.Err.Error()
+			}
+			marker := " "
+			if c.Err == nil && !picked {
+				// فقط اولین مسیر سالم استفاده می‌شود
+				marker = "*"
+				picked = true
+			}
+			location := c.Dir
+			if location == "" {
+				location = "-"
+			}
+			fmt.Fprintf(humanOutput, "%s %d. %-20s %s: %s\n", marker, i+1, c.Source, location, status)
+		}
+		if err != nil {
+			return errorf(codeToolMissing, "%w. Install them with 'dataweaver-cli tools install', or set 'paths.mongo_tools'.", err)
+		}
+
+		fmt.Fprintln(humanOutput)
+		result := &doctorResult{Dir: dir}
+		for _, c := range chain {
+			checked := doctorCandidate{Source: c.Source, Dir: c.Dir}
+			if c.Err != nil {
+				checked.Error = c.Err.Error()
+			}
+			result.Candidates = append(result.Candidates, checked)
+		}
+		for _, tool := range mongodb.RequiredTools {
+			path, _ := engine.FindTool(dir, tool)
+			version := versionOrUnknown(mongodb.ToolVersion(path))
+			fmt.Fprintf(humanOutput, "%-13s %s (version %s)\n", tool+":", path, version)
+			result.Tools = append(result.Tools, doctorTool{Name: tool, Path: path, Version: version})
+		}
+		setResult(result)
+		return nil
+	},
+}
+
+// doctorResult is the outcome of 'tools doctor', reported by '--output json'.
+type doctorResult struct {
+	Dir        string            `json:"dir"`
+	Candidates []doctorCandidate `json:"candidates"`
+	Tools      []doctorTool      `json:"tools"`
+}
+
+type doctorCandidate struct {
+	Source string `json:"source"`
+	Dir    string `json:"dir,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type doctorTool struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// findMongoTools returns the directory with mongodump and mongorestore and reports
+// which binary and version was picked.
+func findMongoTools() (string, error) {
+	dir, chain, err := mongodb.ResolveTools(config.GetString("paths.mongo_tools"), localMongoToolDirs())
+	if err != nil {
+		return "", withCode(codeToolMissing, fmt.Errorf("%w. Run 'dataweaver-cli tools doctor' for details, or 'dataweaver-cli tools install' to install them", err))
+	}
+	for _, c := range chain {
+		if c.Err == nil {
+			mongodumpPath, _ := engine.FindTool(dir, "mongodump")
+			fmt.Fprintf(humanOutput, "Using MongoDB tools: %s (from %s, version %s)\n", mongodumpPath, c.Source, versionOrUnknown(mongodb.ToolVersion(mongodumpPath)))
+			break
+		}
+	}
+	return dir, nil
+}
+
+// localMongoToolDirs returns the bin directories of the versions installed under ./tools,
+// newest first.
+func localMongoToolDirs() []string {
+	installed, err := downloader.InstalledMongoTools(toolsInstallDir)
+	if err != nil {
+		return nil
+	}
+	versions := make([]string, 0, len(installed))
+	for version := range installed {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return downloader.CompareVersions(versions[i], versions[j]) > 0 })
+
+	var dirs []string
+	for _, version := range versions {
+		if binDir, err := downloader.FindBinDir(installed[version], "mongodump"); err == nil {
+			dirs = append(dirs, filepath.Clean(binDir))
+		}
+	}
+	return dirs
+}
+
+func versionOrUnknown(version string) string {
+	if version == "" {
+		return "unknown"
+	}
+	return version
+}
+
+func init() {
+	toolsCmd.AddCommand(toolsDoctorCmd)
+}
@@ -0,0 +1,149 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimestampLayout is the time format embedded in archive names, e.g. backup-2025-06-08_14-30-00.gz.
+const TimestampLayout = "2006-01-02_15-04-05"
+
+// ArchivePrefix is the common prefix of every archive written by the CLI.
+const ArchivePrefix = "backup-"
+
+// Archive is a backup file found in the backup directory.
+type Archive struct {
+	Name string
+	Path string
+	Time time.Time
+	Size int64
+	// Remote is set for archives that are only available in remote storage; Path is
+	// empty for them.
+	Remote bool
+	// Manual is set for files that end in the extension but are not named
+	// "backup-<timestamp><ext>", e.g. copied or renamed by hand. Time is their
+	// modification time. Retention never deletes them (see Managed).
+	Manual bool
+}
+
+// ArchiveName builds the file name of an archive created at t.
+func ArchiveName(t time.Time, ext string) string {
+	return ArchivePrefix + t.Format(TimestampLayout) + ext
+}
+
+// List returns the archives in dir whose name ends with ext, or ext plus
+// EncryptedSuffix, oldest first (see Filter).
+func List(dir, ext string) ([]Archive, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []Archive
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, Archive{Name: entry.Name(), Path: filepath.Join(dir, entry.Name()), Time: info.ModTime(), Size: info.Size()})
+	}
+	return Filter(files, ext), nil
+}
+
+// Filter keeps the files whose name ends with ext, or ext plus EncryptedSuffix, and
+// returns them oldest first.
+// The timestamp is taken from the file name and falls back to the given Time (the
+// modification time) for files that were renamed by hand, which are marked Manual.
+// Files named like archives of another engine (e.g. "backup-<timestamp>.sql.gz" when
+// filtering ".gz") are skipped.
+func Filter(files []Archive, ext string) []Archive {
+	var archives []Archive
+	for _, a := range files {
+		// بکاپ‌های رمزشده پسوند ".enc" اضافه دارند، مثلاً "backup-....gz.enc"
+		nameExt := ext
+		if strings.HasSuffix(a.Name, ext+EncryptedSuffix) {
+			nameExt = ext + EncryptedSuffix
+		}
+		if !strings.HasSuffix(a.Name, nameExt) {
+			continue
+		}
+		if t, ok := parseArchiveTime(a.Name, nameExt); ok {
+			a.Time = t
+		} else if strings.HasPrefix(a.Name, ArchivePrefix) {
+			continue
+		} else {
+			a.Manual = true
+		}
+		archives = append(archives, a)
+	}
+	sort.SliceStable(archives, func(i, j int) bool {
+		return archives[i].Time.Before(archives[j].Time)
+	})
+	return archives
+}
+
+// Managed returns the archives written by the CLI, leaving out the Manual ones.
+func Managed(archives []Archive) []Archive {
+	var managed []Archive
+	for _, a := range archives {
+		if !a.Manual {
+			managed = append(managed, a)
+		}
+	}
+	return managed
+}
+
+// Latest returns the newest archive, or false if there is none.
+func Latest(archives []Archive) (Archive, bool) {
+	if len(archives) == 0 {
+		return Archive{}, false
+	}
+	return archives[len(archives)-1], true
+}
+
+// LatestBefore returns the newest archive created strictly before t.
+func LatestBefore(archives []Archive, t time.Time) (Archive, bool) {
+	for i := len(archives) - 1; i >= 0; i-- {
+		if archives[i].Time.Before(t) {
+			return archives[i], true
+		}
+	}
+	return Archive{}, false
+}
+
+// ParseTime parses a user supplied timestamp. It accepts the archive name layout,
+// RFC 3339 and a few common shorter forms, interpreted in local time.
+func ParseTime(value string) (time.Time, error) {
+	layouts := []string{
+		TimestampLayout,
+		time.RFC3339,
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q (expected e.g. 2025-06-08_14-30-00 or 2025-06-08)", value)
+}
+
+func parseArchiveTime(name, ext string) (time.Time, bool) {
+	if !strings.HasPrefix(name, ArchivePrefix) {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, ArchivePrefix), ext)
+	t, err := time.ParseInLocation(TimestampLayout, stamp, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,121 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/encryption"
+)
+
+// EncryptedSuffix is appended to the extension of encrypted archives, e.g. ".gz.enc".
+const EncryptedSuffix = ".enc"
+
+// PartialSuffix is appended to the name of an archive while it is written.
+const PartialSuffix = ".part"
+
+// File is an archive being written. The data goes to a temporary "<path>.part" file
+// that only becomes the archive when Commit succeeds, so an interrupted or failed
+// backup never leaves a truncated archive behind.
+type File struct {
+	io.Writer
+	path string
+	f    *os.File
+	enc  io.Closer // finishes the encryption; nil for plain archives
+	done bool
+}
+
+// Create starts writing the archive at path. If passphrase is set, everything written
+// to it is encrypted before it reaches the disk. Either Commit or Abort must be called.
+func Create(path, passphrase string) (*File, error) {
+	f, err := os.Create(path + PartialSuffix)
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return &File{Writer: f, path: path, f: f}, nil
+	}
+	ew, err := encryption.NewWriter(f, passphrase)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &File{Writer: ew, path: path, f: f, enc: ew}, nil
+}
+
+// Commit finishes the archive and moves it into place. The partial file is removed
+// if that fails.
+func (a *File) Commit() error {
+	if a.done {
+		return errors.New("archive already closed")
+	}
+	a.done = true
+	var err error
+	if a.enc != nil {
+		err = a.enc.Close()
+	}
+	if cerr := a.f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(a.f.Name(), a.path)
+	}
+	if err != nil {
+		os.Remove(a.f.Name())
+	}
+	return err
+}
+
+// Abort discards the partial archive. It does nothing after Commit, so it can be
+// deferred.
+func (a *File) Abort() {
+	if a.done {
+		return
+	}
+	a.done = true
+	a.f.Close()
+	os.Remove(a.f.Name())
+}
+
+// Open opens an archive for reading and decrypts it if it is encrypted. passphrase is
+// only called for encrypted archives.
+func Open(path string, passphrase func() (string, error)) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := encryption.IsEncrypted(f)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if !encrypted {
+		return f, nil
+	}
+
+	p, err := passphrase()
+	if err == nil && p == "" {
+		err = errors.New("no encryption passphrase configured")
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s is encrypted: %w", path, err)
+	}
+	r, err := encryption.NewReader(f, p)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &decryptedFile{Reader: r, f: f}, nil
+}
+
+type decryptedFile struct {
+	io.Reader
+	f *os.File
+}
+
+func (d *decryptedFile) Close() error {
+	return d.f.Close()
+}
@@ -0,0 +1,133 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// LockFileName is the name of the lock file created in the backup directory while a
+// backup is running.
+const LockFileName = ".dataweaver.lock"
+
+// ErrLocked is returned by Lock if another process is writing a backup to the directory.
+var ErrLocked = errors.New("another backup is already running")
+
+// staleLockAge is how old a lock file without a readable pid must be before it is
+// taken over. Locks are written complete, so such a file is left over from a crash or
+// an older version rather than one that is still being written.
+const staleLockAge = time.Minute
+
+// Lock marks dir as busy so that overlapping backups (e.g. a scheduled one and a manual
+// one) do not write to it at the same time. A lock left behind by a process that no
+// longer runs is taken over. The returned function releases the lock.
+func Lock(dir string) (func(), error) {
+	path := filepath.Join(dir, LockFileName)
+	for attempt := 0; ; attempt++ {
+		err := createLock(path)
+		if err == nil {
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		data, readErr := os.ReadFile(path)
+		if os.IsNotExist(readErr) && attempt == 0 {
+			continue
+		}
+		if attempt > 0 || !lockStale(path, data, readErr) {
+			return nil, fmt.Errorf("%w (pid %s, lock file %s)", ErrLocked, strings.TrimSpace(string(data)), path)
+		}
+		// قفل رهاشده از یک اجرای متوقف‌شده حذف می‌شود
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+}
+
+// createLock creates the lock file at path with the pid of this process. The pid is
+// written to a temporary file first and then linked into place, so that another
+// process never sees a lock file without a pid. It fails with an error satisfying
+// os.IsExist if the lock file exists. On filesystems without hard links the lock file
+// is created with O_EXCL instead.
+func createLock(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), LockFileName+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+	if _, err := fmt.Fprintf(f, "%d\n", os.Getpid()); err != nil {
+		f.Close()
+		return fmt.Errorf("writing lock file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing lock file: %w", err)
+	}
+	err = os.Link(tmp, path)
+	if err != nil && linkUnsupported(err) {
+		return createLockExclusive(path)
+	}
+	return err
+}
+
+// linkUnsupported reports whether err from os.Link means the filesystem has no hard
+// links (FAT, some network and FUSE filesystems) rather than that the link failed.
+func linkUnsupported(err error) bool {
+	return errors.Is(err, errors.ErrUnsupported) || errors.Is(err, syscall.EPERM)
+}
+
+// createLockExclusive creates the lock file at path with O_EXCL and writes the pid of
+// this process to it. Another process may see the file before the pid is written;
+// lockStale then treats it as held until it is older than staleLockAge.
+func createLockExclusive(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("writing lock file: %w", err)
+	}
+	return nil
+}
+
+// lockStale reports whether the lock file at path, read as data, belongs to a process
+// that no longer runs. A lock without a readable pid is only stale once it is older
+// than staleLockAge.
+func lockStale(path string, data []byte, readErr error) bool {
+	if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); readErr == nil && err == nil {
+		return !processAlive(pid)
+	}
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) > staleLockAge
+}
+
+// processAlive reports whether a process with the given pid is running.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// در ویندوز FindProcess فقط برای پردازه‌های موجود موفق می‌شود
+		p.Release()
+		return true
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,147 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// deadPID is a pid no process has: it is above the largest pid Linux and the BSDs
+// hand out.
+const deadPID = 1<<31 - 2
+
+func readLockPID(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("lock file holds %q, not a pid", data)
+	}
+	return pid
+}
+
+func TestLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, LockFileName)
+	unlock, err := Lock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid := readLockPID(t, path); pid != os.Getpid() {
+		t.Errorf("lock file holds pid %d, want %d", pid, os.Getpid())
+	}
+	if _, err := Lock(dir); !errors.Is(err, ErrLocked) {
+		t.Errorf("second Lock = %v, want ErrLocked", err)
+	}
+
+	unlock()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("lock file left after unlock: %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("files left in the backup directory: %v", entries)
+	}
+	unlock, err = Lock(dir)
+	if err != nil {
+		t.Fatalf("Lock after unlock: %v", err)
+	}
+	unlock()
+}
+
+func TestLockStale(t *testing.T) {
+	old := time.Now().Add(-2 * staleLockAge)
+	tests := []struct {
+		name    string
+		content string
+		modTime time.Time
+		taken   bool
+	}{
+		{"pid of a process that no longer runs", fmt.Sprintf("%d\n", deadPID), time.Now(), true},
+		{"pid of a running process", fmt.Sprintf("%d\n", os.Getpid()), old, false},
+		{"fresh lock without a pid", "", time.Now(), false},
+		{"old lock without a pid", "", old, true},
+		{"old lock with garbage", "not a pid", old, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, LockFileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(path, tt.modTime, tt.modTime); err != nil {
+				t.Fatal(err)
+			}
+			unlock, err := Lock(dir)
+			if !tt.taken {
+				if !errors.Is(err, ErrLocked) {
+					t.Fatalf("Lock = %v, want ErrLocked", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lock = %v, want the stale lock taken over", err)
+			}
+			defer unlock()
+			if pid := readLockPID(t, path); pid != os.Getpid() {
+				t.Errorf("lock file holds pid %d, want %d", pid, os.Getpid())
+			}
+		})
+	}
+}
+
+func TestCreateLockExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFileName)
+	if err := createLockExclusive(path); err != nil {
+		t.Fatal(err)
+	}
+	if pid := readLockPID(t, path); pid != os.Getpid() {
+		t.Errorf("lock file holds pid %d, want %d", pid, os.Getpid())
+	}
+	if err := createLockExclusive(path); !os.IsExist(err) {
+		t.Errorf("second createLockExclusive = %v, want an error satisfying os.IsExist", err)
+	}
+}
+
+func TestLinkUnsupported(t *testing.T) {
+	link := func(errno syscall.Errno) error {
+		return &os.LinkError{Op: "link", Old: "a", New: "b", Err: errno}
+	}
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{link(syscall.EPERM), true},
+		{fmt.Errorf("link: %w", errors.ErrUnsupported), true},
+		{link(syscall.EEXIST), false},
+		{link(syscall.EACCES), false},
+		{link(syscall.ENOSPC), false},
+	}
+	if runtime.GOOS != "windows" {
+		// در ویندوز این خطاها ساختگی‌اند و به ErrUnsupported نگاشت نمی‌شوند
+		tests = append(tests, []struct {
+			err  error
+			want bool
+		}{
+			{link(syscall.ENOTSUP), true},
+			{link(syscall.EOPNOTSUPP), true},
+			{link(syscall.ENOSYS), true},
+		}...)
+	}
+	for _, tt := range tests {
+		if got := linkUnsupported(tt.err); got != tt.want {
+			t.Errorf("linkUnsupported(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
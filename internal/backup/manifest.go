@@ -0,0 +1,113 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Manifest is the JSON sidecar written next to every backup archive.
+type Manifest struct {
+	Archive            string    `json:"archive"`
+	Engine             string    `json:"engine"`
+	SHA256             string    `json:"sha256"`
+	Size               int64     `json:"size"`
+	SourceHost         string    `json:"source_host,omitempty"`
+	ToolVersion        string    `json:"tool_version,omitempty"`
+	CLIVersion         string    `json:"cli_version,omitempty"`
+	StartedAt          time.Time `json:"started_at"`
+	FinishedAt         time.Time `json:"finished_at"`
+	Namespaces         []string  `json:"namespaces"`
+	ExcludedNamespaces []string  `json:"excluded_namespaces,omitempty"`
+	Encrypted          bool      `json:"encrypted,omitempty"`
+	// Oplog is set for MongoDB snapshots that include the oplog and OplogSlice for
+	// incremental oplog archives. OplogStart and OplogEnd are the oplog positions
+	// ("<seconds>:<ordinal>") the archive covers.
+	Oplog      bool   `json:"oplog,omitempty"`
+	OplogSlice bool   `json:"oplog_slice,omitempty"`
+	OplogStart string `json:"oplog_start,omitempty"`
+	OplogEnd   string `json:"oplog_end,omitempty"`
+	// SafetySnapshotFor is set for snapshots of a restore target taken before the
+	// named archive was restored into it.
+	SafetySnapshotFor string `json:"safety_snapshot_for,omitempty"`
+}
+
+// ManifestPath returns the path of the sidecar file for the given archive.
+func ManifestPath(archivePath string) string {
+	return archivePath + ".json"
+}
+
+// WriteManifest stores m next to the archive at archivePath.
+func WriteManifest(archivePath string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	return os.WriteFile(ManifestPath(archivePath), append(data, '\n'), 0644)
+}
+
+// ReadManifest loads the sidecar of the archive at archivePath.
+// It returns an error satisfying os.IsNotExist if the archive has no manifest.
+func ReadManifest(archivePath string) (*Manifest, error) {
+	data, err := os.ReadFile(ManifestPath(archivePath))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("decoding manifest %s: %w", ManifestPath(archivePath), err)
+	}
+	return &m, nil
+}
+
+// Checksum returns the hex encoded SHA-256 and the size of the file at path.
+func Checksum(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// Verify recomputes the checksum of the archive and compares it with the manifest.
+func Verify(archivePath string, m *Manifest) error {
+	if m.SHA256 == "" {
+		return fmt.Errorf("manifest for %s has no checksum", archivePath)
+	}
+	sum, size, err := Checksum(archivePath)
+	if err != nil {
+		return err
+	}
+	if size != m.Size {
+		return fmt.Errorf("size mismatch for %s: manifest says %d bytes, file has %d", archivePath, m.Size, size)
+	}
+	if sum != m.SHA256 {
+		return fmt.Errorf("checksum mismatch for %s: manifest says %s, file has %s", archivePath, m.SHA256, sum)
+	}
+	return nil
+}
+
+// FormatSize renders a byte count in a human readable unit.
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
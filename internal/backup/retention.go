@@ -0,0 +1,145 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RetentionPolicy describes which archives survive a prune, using
+// grandfather-father-son rotation. An archive is kept if any rule keeps it.
+type RetentionPolicy struct {
+	KeepLast     int   // the N most recent archives
+	KeepDaily    int   // the newest archive of each of the last N days that have one
+	KeepWeekly   int   // the newest archive of each of the last N ISO weeks that have one
+	KeepMonthly  int   // the newest archive of each of the last N months that have one
+	MaxTotalSize int64 // upper bound for the kept archives, in bytes; older archives go first
+}
+
+// IsZero reports whether the policy has no rules, in which case nothing is pruned.
+func (p RetentionPolicy) IsZero() bool {
+	return p.KeepLast <= 0 && p.KeepDaily <= 0 && p.KeepWeekly <= 0 && p.KeepMonthly <= 0 && p.MaxTotalSize <= 0
+}
+
+// Plan splits archives into the ones to keep and the ones to delete. Both results are
+// ordered newest first. The newest archive is always kept. Manual archives are left
+// out of both: only files named by the CLI are ever deleted.
+func (p RetentionPolicy) Plan(archives []Archive) (keep, remove []Archive) {
+	archives = Managed(archives)
+	if p.IsZero() || len(archives) == 0 {
+		return sortNewestFirst(archives), nil
+	}
+	sorted := sortNewestFirst(archives)
+
+	kept := make([]bool, len(sorted))
+	kept[0] = true
+	hasCountRules := p.KeepLast > 0 || p.KeepDaily > 0 || p.KeepWeekly > 0 || p.KeepMonthly > 0
+	if hasCountRules {
+		for i := 0; i < p.KeepLast && i < len(sorted); i++ {
+			kept[i] = true
+		}
+		keepPerBucket(sorted, kept, p.KeepDaily, func(a Archive) string { return a.Time.Format("2006-01-02") })
+		keepPerBucket(sorted, kept, p.KeepWeekly, func(a Archive) string {
+			year, week := a.Time.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		})
+		keepPerBucket(sorted, kept, p.KeepMonthly, func(a Archive) string { return a.Time.Format("2006-01") })
+	} else {
+		// فقط محدودیت حجم تعریف شده؛ همه بکاپ‌ها کاندید نگهداری هستند
+		for i := range kept {
+			kept[i] = true
+		}
+	}
+
+	// اعمال سقف حجم کل: از جدیدترین به قدیمی‌ترین جمع می‌زنیم
+	if p.MaxTotalSize > 0 {
+		var total int64
+		for i, a := range sorted {
+			if !kept[i] {
+				continue
+			}
+			total += a.Size
+			if i > 0 && total > p.MaxTotalSize {
+				kept[i] = false
+			}
+		}
+	}
+
+	for i, a := range sorted {
+		if kept[i] {
+			keep = append(keep, a)
+		} else {
+			remove = append(remove, a)
+		}
+	}
+	return keep, remove
+}
+
+// keepPerBucket marks the newest archive of each of the first n distinct buckets.
+func keepPerBucket(sorted []Archive, kept []bool, n int, bucket func(Archive) string) {
+	if n <= 0 {
+		return
+	}
+	seen := make(map[string]bool)
+	for i, a := range sorted {
+		key := bucket(a)
+		if seen[key] {
+			continue
+		}
+		if len(seen) == n {
+			return
+		}
+		seen[key] = true
+		kept[i] = true
+	}
+}
+
+func sortNewestFirst(archives []Archive) []Archive {
+	sorted := append([]Archive(nil), archives...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.After(sorted[j].Time)
+	})
+	return sorted
+}
+
+// Remove deletes an archive together with its manifest.
+func Remove(a Archive) error {
+	if err := os.Remove(a.Path); err != nil {
+		return err
+	}
+	if err := os.Remove(ManifestPath(a.Path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ParseSize parses sizes such as "500MB", "10GiB" or "1073741824" into bytes.
+// Decimal (KB, MB, GB, TB) and binary (KiB, MiB, GiB, TiB) units are supported.
+func ParseSize(value string) (int64, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return 0, nil
+	}
+	i := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' })
+	number, unit := s, ""
+	if i >= 0 {
+		number, unit = strings.TrimSpace(s[:i]), strings.ToUpper(strings.TrimSpace(s[i:]))
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	multipliers := map[string]float64{
+		"": 1, "B": 1,
+		"K": 1e3, "KB": 1e3, "M": 1e6, "MB": 1e6, "G": 1e9, "GB": 1e9, "T": 1e12, "TB": 1e12,
+		"KIB": 1 << 10, "MIB": 1 << 20, "GIB": 1 << 30, "TIB": 1 << 40,
+	}
+	m, ok := multipliers[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit %q in %q", unit, value)
+	}
+	return int64(n * m), nil
+}
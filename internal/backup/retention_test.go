@@ -0,0 +1,161 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// history are the archives the retention tests start from, newest first, 100 bytes
+// each. 2025-03-31 is the Monday of ISO week 14; 03-24 to 03-30 is week 13.
+var history = []string{
+	"2025-03-31 18:00",
+	"2025-03-31 12:00",
+	"2025-03-30 10:00",
+	"2025-03-29 10:00",
+	"2025-03-24 10:00",
+	"2025-03-17 10:00",
+	"2025-02-28 10:00",
+	"2025-02-10 10:00",
+	"2025-01-15 10:00",
+	"2024-12-01 10:00",
+}
+
+func archivesAt(t *testing.T, times []string) []Archive {
+	t.Helper()
+	var files []Archive
+	for _, s := range times {
+		at, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := ArchiveName(at, ".gz")
+		files = append(files, Archive{Name: name, Path: name, Size: 100})
+	}
+	return Filter(files, ".gz")
+}
+
+func archiveTimes(archives []Archive) []string {
+	var times []string
+	for _, a := range archives {
+		times = append(times, a.Time.Format("2006-01-02 15:04"))
+	}
+	return times
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		keep   []string
+	}{
+		{"no rules", RetentionPolicy{}, history},
+		{"keep last", RetentionPolicy{KeepLast: 3}, history[:3]},
+		{"keep daily", RetentionPolicy{KeepDaily: 3}, []string{"2025-03-31 18:00", "2025-03-30 10:00", "2025-03-29 10:00"}},
+		{"keep weekly", RetentionPolicy{KeepWeekly: 3}, []string{"2025-03-31 18:00", "2025-03-30 10:00", "2025-03-17 10:00"}},
+		{"keep monthly", RetentionPolicy{KeepMonthly: 3}, []string{"2025-03-31 18:00", "2025-02-28 10:00", "2025-01-15 10:00"}},
+		{"more buckets than archives", RetentionPolicy{KeepMonthly: 12}, []string{"2025-03-31 18:00", "2025-02-28 10:00", "2025-01-15 10:00", "2024-12-01 10:00"}},
+		// The rules overlap: an archive is kept if any of them keeps it.
+		{"overlapping rules", RetentionPolicy{KeepLast: 2, KeepDaily: 2, KeepWeekly: 2, KeepMonthly: 4},
+			[]string{"2025-03-31 18:00", "2025-03-31 12:00", "2025-03-30 10:00", "2025-02-28 10:00", "2025-01-15 10:00", "2024-12-01 10:00"}},
+		{"max total size only", RetentionPolicy{MaxTotalSize: 350}, history[:3]},
+		{"max total size exactly reached", RetentionPolicy{MaxTotalSize: 300}, history[:3]},
+		{"max total size over the kept archives", RetentionPolicy{KeepMonthly: 3, MaxTotalSize: 250}, []string{"2025-03-31 18:00", "2025-02-28 10:00"}},
+		{"newest kept over the size limit", RetentionPolicy{MaxTotalSize: 50}, history[:1]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archives := archivesAt(t, history)
+			keep, remove := tt.policy.Plan(archives)
+			if got := archiveTimes(keep); !reflect.DeepEqual(got, tt.keep) {
+				t.Fatalf("kept %v, want %v", got, tt.keep)
+			}
+			if len(keep)+len(remove) != len(archives) {
+				t.Fatalf("kept %d and removed %d of %d archives", len(keep), len(remove), len(archives))
+			}
+		})
+	}
+}
+
+// TestPlanManualFiles checks that files not named like CLI archives are never pruned,
+// however old they are.
+func TestPlanManualFiles(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().AddDate(-2, 0, 0)
+	names := []string{
+		"backup-2025-03-31_10-00-00.gz",
+		"backup-2025-03-30_10-00-00.gz",
+		"backup-2025-03-29_10-00-00.gz.enc",
+		"dump.gz",                           // renamed by hand
+		"old.sql.gz",                        // another engine's extension, named by hand
+		"backup-2025-03-28_10-00-00.sql.gz", // an archive of another engine
+		"backup-latest.gz",                  // our prefix but no timestamp
+		"notes.txt",
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	archives, err := List(dir, ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed []string
+	for _, a := range archives {
+		if a.Manual {
+			listed = append(listed, a.Name)
+		}
+	}
+	if want := []string{"dump.gz", "old.sql.gz"}; !reflect.DeepEqual(listed, want) {
+		t.Errorf("manual files %v, want %v", listed, want)
+	}
+
+	keep, remove := RetentionPolicy{KeepLast: 1, MaxTotalSize: 1}.Plan(archives)
+	var kept, removed []string
+	for _, a := range keep {
+		kept = append(kept, a.Name)
+	}
+	for _, a := range remove {
+		removed = append(removed, a.Name)
+	}
+	if want := []string{"backup-2025-03-31_10-00-00.gz"}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept %v, want %v", kept, want)
+	}
+	if want := []string{"backup-2025-03-30_10-00-00.gz", "backup-2025-03-29_10-00-00.gz.enc"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed %v, want %v", removed, want)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"1073741824", 1 << 30, false},
+		{"500MB", 500e6, false},
+		{"500 mb", 500e6, false},
+		{"1.5GB", 1.5e9, false},
+		{"10GiB", 10 << 30, false},
+		{"2TiB", 2 << 40, false},
+		{"100B", 100, false},
+		{"10XB", 0, true},
+		{"GB", 0, true},
+		{"ten", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -10,14 +10,107 @@ import (
 
 // Config struct to hold all configuration parameters
 type Config struct {
+	DefaultProfile string             `mapstructure:"default_profile"`
+	Profiles       map[string]Profile `mapstructure:"profiles"`
+	Profile        `mapstructure:",squash"`
+	Daemon         struct {
+		LogFile string `mapstructure:"log_file"`
+	} `mapstructure:"daemon"`
+}
+
+// Profile holds the settings that can be defined globally or per named profile.
+// Values missing from a profile fall back to the global ones.
+type Profile struct {
 	MongoDB struct {
 		RemoteURI string `mapstructure:"remote_uri"`
 		LocalURI  string `mapstructure:"local_uri"`
+		Backup    struct {
+			DB                        string   `mapstructure:"db"`
+			Collection                string   `mapstructure:"collection"`
+			ExcludeCollections        []string `mapstructure:"exclude_collections"`
+			ExcludeCollectionPrefixes []string `mapstructure:"exclude_collection_prefixes"`
+			Oplog                     bool     `mapstructure:"oplog"`
+		} `mapstructure:"backup"`
+		Restore struct {
+			TargetDB string `mapstructure:"target_db"`
+			// NSMap holds "from -> to" namespace rules, e.g. "app.* -> app_copy.*".
+			NSMap     []string `mapstructure:"ns_map"`
+			NSInclude []string `mapstructure:"ns_include"`
+			NSExclude []string `mapstructure:"ns_exclude"`
+			Staged    bool     `mapstructure:"staged"`
+		} `mapstructure:"restore"`
 	} `mapstructure:"mongodb"`
+	Postgres struct {
+		RemoteURI string `mapstructure:"remote_uri"`
+		LocalURI  string `mapstructure:"local_uri"`
+		Backup    struct {
+			Schemas       []string `mapstructure:"schemas"`
+			Tables        []string `mapstructure:"tables"`
+			ExcludeTables []string `mapstructure:"exclude_tables"`
+		} `mapstructure:"backup"`
+	} `mapstructure:"postgres"`
+	MySQL struct {
+		RemoteURI         string `mapstructure:"remote_uri"`
+		LocalURI          string `mapstructure:"local_uri"`
+		SingleTransaction *bool  `mapstructure:"single_transaction"`
+		Backup            struct {
+			Databases []string `mapstructure:"databases"`
+		} `mapstructure:"backup"`
+	} `mapstructure:"mysql"`
 	Paths struct {
-		Backup     string `mapstructure:"backup"`
-		MongoTools string `mapstructure:"mongo_tools"`
+		Backup        string `mapstructure:"backup"`
+		MongoTools    string `mapstructure:"mongo_tools"`
+		PostgresTools string `mapstructure:"postgres_tools"`
+		MySQLTools    string `mapstructure:"mysql_tools"`
 	} `mapstructure:"paths"`
+	Restore struct {
+		// Drop is nil when unset; interactive restores then ask whether to drop.
+		Drop     *bool `mapstructure:"drop"`
+		Snapshot bool  `mapstructure:"snapshot"`
+	} `mapstructure:"restore"`
+	Tools struct {
+		// Catalog is the path of a tools catalog that extends the built-in one.
+		Catalog string `mapstructure:"catalog"`
+	} `mapstructure:"tools"`
+	Retention struct {
+		KeepLast     int    `mapstructure:"keep_last"`
+		KeepDaily    int    `mapstructure:"keep_daily"`
+		KeepWeekly   int    `mapstructure:"keep_weekly"`
+		KeepMonthly  int    `mapstructure:"keep_monthly"`
+		MaxTotalSize string `mapstructure:"max_total_size"`
+	} `mapstructure:"retention"`
+	Encryption struct {
+		// Passphrase enables encryption of new archives; usually a secret reference.
+		Passphrase string `mapstructure:"passphrase"`
+	} `mapstructure:"encryption"`
+	Storage struct {
+		// Type is one of "local", "s3", "sftp" or "webdav"; empty disables remote storage.
+		Type   string `mapstructure:"type"`
+		Upload bool   `mapstructure:"upload"`
+		Path   string `mapstructure:"path"`
+		// S3-compatible object storage
+		Endpoint  string `mapstructure:"endpoint"`
+		Bucket    string `mapstructure:"bucket"`
+		Prefix    string `mapstructure:"prefix"`
+		Region    string `mapstructure:"region"`
+		AccessKey string `mapstructure:"access_key"`
+		SecretKey string `mapstructure:"secret_key"`
+		PathStyle bool   `mapstructure:"path_style"`
+		// SFTP
+		Host                  string `mapstructure:"host"`
+		Port                  int    `mapstructure:"port"`
+		KeyFile               string `mapstructure:"key_file"`
+		KnownHosts            string `mapstructure:"known_hosts"`
+		InsecureIgnoreHostKey bool   `mapstructure:"insecure_ignore_host_key"`
+		// WebDAV
+		URL string `mapstructure:"url"`
+		// SFTP and WebDAV credentials; Password is usually a secret reference.
+		User     string `mapstructure:"user"`
+		Password string `mapstructure:"password"`
+	} `mapstructure:"storage"`
+	// Schedule maps an engine ("mongo", "postgres" or "mysql") to the cron expression
+	// at which the daemon backs it up.
+	Schedule map[string]string `mapstructure:"schedule"`
 }
 
 // Global variable to hold the loaded configuration
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+// activeProfile is the profile selected with --profile or from the interactive menu.
+// When empty, 'default_profile' from the config file is used.
+var activeProfile string
+
+// SetActiveProfile selects the profile used by GetString and friends.
+func SetActiveProfile(name string) {
+	activeProfile = name
+}
+
+// ActiveProfile returns the selected profile, the configured default profile,
+// or an empty string if profiles are not used. A default profile that no longer
+// exists is ignored.
+func ActiveProfile() string {
+	if activeProfile != "" {
+		return activeProfile
+	}
+	if name := viper.GetString("default_profile"); name != "" && ProfileExists(name) {
+		return name
+	}
+	return ""
+}
+
+// ProfileNames returns the names of all configured profiles, sorted.
+func ProfileNames() []string {
+	var names []string
+	for name := range viper.GetStringMap("profiles") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileExists reports whether a profile with the given name is configured.
+func ProfileExists(name string) bool {
+	return viper.IsSet("profiles." + name)
+}
+
+// ValidateActiveProfile returns an error if the profile selected with SetActiveProfile does not exist.
+func ValidateActiveProfile() error {
+	if activeProfile == "" || ProfileExists(activeProfile) {
+		return nil
+	}
+	return fmt.Errorf("profile %q is not configured (available: %v)", activeProfile, ProfileNames())
+}
+
+// ProfileKey returns the full key of a setting inside the named profile.
+func ProfileKey(profile, key string) string {
+	return "profiles." + profile + "." + key
+}
+
+// Key resolves a setting to the active profile if the profile defines it,
+// otherwise to the top-level (global) key.
+func Key(key string) string {
+	if name := ActiveProfile(); name != "" {
+		if k := ProfileKey(name, key); viper.IsSet(k) {
+			return k
+		}
+	}
+	return key
+}
+
+// IsSet reports whether a setting is defined in the active profile or globally.
+func IsSet(key string) bool {
+	return viper.IsSet(Key(key))
+}
+
+// GetString returns a setting of the active profile, falling back to the global value.
+func GetString(key string) string {
+	return viper.GetString(Key(key))
+}
+
+// GetInt is the int counterpart of GetString.
+func GetInt(key string) int {
+	return viper.GetInt(Key(key))
+}
+
+// GetBool is the bool counterpart of GetString.
+func GetBool(key string) bool {
+	return viper.GetBool(Key(key))
+}
+
+// GetStringSlice is the []string counterpart of GetString.
+func GetStringSlice(key string) []string {
+	return viper.GetStringSlice(Key(key))
+}
+
+// Set stores a setting in the active profile, or globally if no profile is active.
+func Set(key string, value any) {
+	if name := ActiveProfile(); name != "" {
+		viper.Set(ProfileKey(name, key), value)
+		return
+	}
+	viper.Set(key, value)
+}
+
+// RemoveProfile deletes a profile from the in-memory configuration.
+// viper cannot unset keys, so the settings are rebuilt without the profile;
+// the caller is responsible for writing the configuration back to disk.
+func RemoveProfile(name string) error {
+	if !ProfileExists(name) {
+		return fmt.Errorf("profile %q is not configured", name)
+	}
+	settings := viper.AllSettings()
+	if profiles, ok := settings["profiles"].(map[string]any); ok {
+		delete(profiles, name)
+	}
+	if viper.GetString("default_profile") == name {
+		delete(settings, "default_profile")
+	}
+	if activeProfile == name {
+		activeProfile = ""
+	}
+
+	configFile := viper.ConfigFileUsed()
+	viper.Reset()
+	viper.SetConfigType("yaml")
+	if configFile != "" {
+		viper.SetConfigFile(configFile)
+	}
+	return viper.MergeConfigMap(settings)
+}
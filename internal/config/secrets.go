@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/secrets"
+	"github.com/spf13/viper"
+)
+
+// SecretsPassphraseEnv names the environment variable holding the passphrase of the
+// secrets file, for scripts and scheduled jobs.
+const SecretsPassphraseEnv = "DATAWEAVER_SECRETS_PASSPHRASE"
+
+// PassphraseFunc asks for the passphrase of the secrets file when SecretsPassphraseEnv
+// is not set. The CLI sets it to an interactive prompt.
+var PassphraseFunc func() (string, error)
+
+var secretStore *secrets.Store
+
+// SecretsFilePath returns the path of the encrypted secrets file: 'secrets.file' or
+// ~/.dataweaver-cli/secrets.enc.
+func SecretsFilePath() string {
+	if path := viper.GetString("secrets.file"); path != "" {
+		return ExpandHome(path)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "secrets.enc"
+	}
+	return filepath.Join(home, ".dataweaver-cli", "secrets.enc")
+}
+
+// OpenSecrets opens the encrypted secrets file, asking for the passphrase once per run.
+func OpenSecrets() (*secrets.Store, error) {
+	if secretStore != nil {
+		return secretStore, nil
+	}
+	passphrase := os.Getenv(SecretsPassphraseEnv)
+	if passphrase == "" {
+		if PassphraseFunc == nil {
+			return nil, fmt.Errorf("the secrets file is locked; set %s", SecretsPassphraseEnv)
+		}
+		var err error
+		if passphrase, err = PassphraseFunc(); err != nil {
+			return nil, err
+		}
+	}
+	store, err := secrets.Open(SecretsFilePath(), passphrase)
+	if err != nil {
+		return nil, err
+	}
+	secretStore = store
+	return store, nil
+}
+
+// ResolveSecret returns the value a setting refers to:
+//
+//	env:NAME     the environment variable NAME
+//	file:PATH    the contents of the file at PATH, without a trailing newline
+//	secret:NAME  the entry NAME of the encrypted secrets file
+//
+// Any other value is returned unchanged.
+func ResolveSecret(value string) (string, error) {
+	kind, ref, ok := strings.Cut(value, ":")
+	if !ok {
+		return value, nil
+	}
+	switch kind {
+	case "env":
+		v, found := os.LookupEnv(ref)
+		if !found {
+			return "", fmt.Errorf("environment variable %s is not set", ref)
+		}
+		return v, nil
+	case "file":
+		data, err := os.ReadFile(ExpandHome(ref))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case "secret":
+		store, err := OpenSecrets()
+		if err != nil {
+			return "", err
+		}
+		v, found := store.Get(ref)
+		if !found {
+			return "", fmt.Errorf("secret %q not found in %s", ref, SecretsFilePath())
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+// GetSecret returns a setting of the active profile with secret references resolved.
+func GetSecret(key string) (string, error) {
+	v, err := ResolveSecret(GetString(key))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", key, err)
+	}
+	return v, nil
+}
+
+// PasswordKey returns the key holding the password of a connection string setting,
+// e.g. "mongodb.remote_password" for "mongodb.remote_uri".
+func PasswordKey(uriKey string) string {
+	return strings.TrimSuffix(uriKey, "_uri") + "_password"
+}
+
+// GetURI returns a connection string setting of the active profile with secret
+// references resolved. If the matching password setting (see PasswordKey) is set and
+// the URI has no password of its own, the resolved password is inserted into it.
+func GetURI(key string) (string, error) {
+	uri, err := GetSecret(key)
+	if err != nil || uri == "" {
+		return uri, err
+	}
+	passwordKey := PasswordKey(key)
+	if _, inline := SplitPassword(uri); inline != "" || GetString(passwordKey) == "" {
+		return uri, nil
+	}
+	password, err := GetSecret(passwordKey)
+	if err != nil {
+		return "", err
+	}
+	uri, err = WithPassword(uri, password)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", key, err)
+	}
+	return uri, nil
+}
+
+// SplitPassword removes the password from the userinfo of a connection string and
+// returns it unescaped. URIs without a password are returned unchanged.
+func SplitPassword(uri string) (string, string) {
+	prefix, userinfo, rest, ok := splitUserinfo(uri)
+	if !ok {
+		return uri, ""
+	}
+	user, password, found := strings.Cut(userinfo, ":")
+	if !found {
+		return uri, ""
+	}
+	if unescaped, err := url.PathUnescape(password); err == nil {
+		password = unescaped
+	}
+	return prefix + user + "@" + rest, password
+}
+
+// WithPassword inserts a password into the userinfo of a connection string, which
+// must contain a user name.
+func WithPassword(uri, password string) (string, error) {
+	prefix, userinfo, rest, ok := splitUserinfo(uri)
+	if !ok {
+		return "", errors.New("a password is configured but the URI has no user name")
+	}
+	user, _, _ := strings.Cut(userinfo, ":")
+	// QueryEscape همه کاراکترهای رزرو‌شده را کد می‌کند؛ فقط فاصله باید %20 باشد
+	escaped := strings.ReplaceAll(url.QueryEscape(password), "+", "%20")
+	return prefix + user + ":" + escaped + "@" + rest, nil
+}
+
+// splitUserinfo splits "scheme://userinfo@rest". net/url is not used because it
+// rejects the multi-host strings MongoDB accepts.
+func splitUserinfo(uri string) (prefix, userinfo, rest string, ok bool) {
+	i := strings.Index(uri, "://")
+	if i < 0 {
+		return "", "", "", false
+	}
+	prefix, authority := uri[:i+3], uri[i+3:]
+	end := strings.IndexAny(authority, "/?#")
+	if end < 0 {
+		end = len(authority)
+	}
+	at := strings.LastIndex(authority[:end], "@")
+	if at < 0 {
+		return "", "", "", false
+	}
+	return prefix, authority[:at], authority[at+1:], true
+}
+
+// ExpandHome replaces a leading "~/" in path with the home directory.
+func ExpandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/secrets"
+	"github.com/spf13/viper"
+)
+
+func TestResolveSecret(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("from-file\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	secretsFile := filepath.Join(dir, "secrets.enc")
+	store, err := secrets.Open(secretsFile, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Set("mongo", "from-store")
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DW_TEST_PASSWORD", "from-env")
+	t.Setenv("DW_TEST_EMPTY", "")
+	t.Setenv(SecretsPassphraseEnv, "passphrase")
+	viper.Set("secrets.file", secretsFile)
+	secretStore = nil
+	t.Cleanup(func() {
+		viper.Set("secrets.file", "")
+		secretStore = nil
+	})
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"plain value", "plain value", false},
+		{"mongodb://admin:pw@db/app", "mongodb://admin:pw@db/app", false},
+		{"unknown:kind", "unknown:kind", false},
+		{"env:DW_TEST_PASSWORD", "from-env", false},
+		{"env:DW_TEST_EMPTY", "", false},
+		{"env:DW_TEST_MISSING", "", true},
+		{"file:" + passwordFile, "from-file", false},
+		{"file:" + filepath.Join(dir, "missing"), "", true},
+		{"secret:mongo", "from-store", false},
+		{"secret:missing", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ResolveSecret(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ResolveSecret(%q) = %q, %v; want %q (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestWithPassword(t *testing.T) {
+	tests := []struct {
+		uri, password, want string
+	}{
+		{"mongodb://admin@db/app", "p@ss word", "mongodb://admin:p%40ss%20word@db/app"},
+		{"mysql://backup:old@db:3306/shop", "new", "mysql://backup:new@db:3306/shop"},
+		{"mongodb://admin@h1,h2/?replicaSet=rs0", "pw", "mongodb://admin:pw@h1,h2/?replicaSet=rs0"},
+	}
+	for _, tt := range tests {
+		got, err := WithPassword(tt.uri, tt.password)
+		if err != nil || got != tt.want {
+			t.Errorf("WithPassword(%q, %q) = %q, %v; want %q", tt.uri, tt.password, got, err, tt.want)
+		}
+		if _, password := SplitPassword(got); password != tt.password {
+			t.Errorf("SplitPassword(%q) = %q, want %q", got, password, tt.password)
+		}
+	}
+	if _, err := WithPassword("mongodb://db/app", "pw"); err == nil {
+		t.Error("WithPassword without a user name succeeded")
+	}
+}
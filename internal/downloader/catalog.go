@@ -0,0 +1,127 @@
+package downloader
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The checksums in catalog.json are refreshed from the release feed published by
+// MongoDB with 'go generate ./internal/downloader'.
+//go:generate go run ./pincatalog catalog.json
+
+//go:embed catalog.json
+var embeddedCatalog []byte
+
+// Catalog lists the tool versions the CLI knows how to install.
+type Catalog struct {
+	MongoTools ToolCatalog `json:"mongodb-database-tools"`
+}
+
+// ToolCatalog lists the releases of one tool package.
+type ToolCatalog struct {
+	Default  string        `json:"default"`
+	Versions []ToolVersion `json:"versions"`
+}
+
+// ToolVersion is one release. SHA256 pins the checksums of its archives by file name.
+// A pinned checksum is authoritative: the release feed published by the vendor is
+// only consulted for archives that are not pinned.
+type ToolVersion struct {
+	Version string            `json:"version"`
+	SHA256  map[string]string `json:"sha256,omitempty"`
+	Notes   string            `json:"notes,omitempty"`
+}
+
+// LoadCatalog returns the embedded catalog, merged with the override file at
+// overridePath if it exists. Versions in the override replace embedded entries
+// with the same version number; a non-empty default replaces the embedded default.
+func LoadCatalog(overridePath string) (*Catalog, error) {
+	var catalog Catalog
+	if err := json.Unmarshal(embeddedCatalog, &catalog); err != nil {
+		return nil, fmt.Errorf("decoding embedded catalog: %w", err)
+	}
+	if overridePath == "" {
+		return &catalog, nil
+	}
+	data, err := os.ReadFile(overridePath)
+	if os.IsNotExist(err) {
+		return &catalog, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var override Catalog
+	if err := json.Unmarshal(data, &override); err != nil {
+		return nil, fmt.Errorf("decoding catalog %s: %w", overridePath, err)
+	}
+	catalog.MongoTools.merge(override.MongoTools)
+	return &catalog, nil
+}
+
+func (c *ToolCatalog) merge(o ToolCatalog) {
+	if o.Default != "" {
+		c.Default = o.Default
+	}
+	for _, v := range o.Versions {
+		replaced := false
+		for i := range c.Versions {
+			if c.Versions[i].Version == v.Version {
+				c.Versions[i] = v
+				replaced = true
+			}
+		}
+		if !replaced {
+			c.Versions = append(c.Versions, v)
+		}
+	}
+	sort.SliceStable(c.Versions, func(i, j int) bool {
+		return CompareVersions(c.Versions[i].Version, c.Versions[j].Version) > 0
+	})
+}
+
+// Find returns the catalog entry of a version.
+func (c *ToolCatalog) Find(version string) (ToolVersion, bool) {
+	for _, v := range c.Versions {
+		if v.Version == version {
+			return v, true
+		}
+	}
+	return ToolVersion{}, false
+}
+
+// PinnedSHA256 returns the pinned checksum of an archive file name, or "".
+func (c *ToolCatalog) PinnedSHA256(fileName string) string {
+	for _, v := range c.Versions {
+		if sum := v.SHA256[fileName]; sum != "" {
+			return strings.ToLower(sum)
+		}
+	}
+	return ""
+}
+
+// CompareVersions compares dotted numeric versions such as "100.9.5" and "100.12.0".
+// It returns -1, 0 or 1.
+func CompareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
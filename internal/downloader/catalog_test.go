@@ -0,0 +1,84 @@
+package downloader
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestEmbeddedCatalogPinned fails while the built-in catalog has no checksums for the
+// default version; refresh them with 'go generate ./internal/downloader'.
+func TestEmbeddedCatalogPinned(t *testing.T) {
+	catalog, err := LoadCatalog("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	def, ok := catalog.MongoTools.Find(catalog.MongoTools.Default)
+	if !ok {
+		t.Fatalf("default version %s is not in the catalog", catalog.MongoTools.Default)
+	}
+	if len(def.SHA256) == 0 {
+		t.Fatalf("default version %s has no pinned checksums; run 'go generate ./internal/downloader'", def.Version)
+	}
+	for _, v := range catalog.MongoTools.Versions {
+		for name, sum := range v.SHA256 {
+			if !strings.Contains(name, v.Version) {
+				t.Errorf("%s: archive %s does not belong to this version", v.Version, name)
+			}
+			if b, err := hex.DecodeString(sum); err != nil || len(b) != 32 {
+				t.Errorf("%s: %s has an invalid SHA-256 %q", v.Version, name, sum)
+			}
+		}
+	}
+}
+
+func TestLoadCatalogOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools-catalog.json")
+	override := `{"mongodb-database-tools": {"default": "100.13.0", "versions": [
+		{"version": "100.13.0", "sha256": {"mongodb-database-tools-ubuntu2204-x86_64-100.13.0.tgz": "ABCDEF"}},
+		{"version": "100.9.4", "notes": "replaced"}
+	]}}`
+	if err := os.WriteFile(path, []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	catalog, err := LoadCatalog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools := catalog.MongoTools
+	if tools.Default != "100.13.0" || tools.Versions[0].Version != "100.13.0" {
+		t.Errorf("default %s, newest %s; want 100.13.0 for both", tools.Default, tools.Versions[0].Version)
+	}
+	if v, _ := tools.Find("100.9.4"); v.Notes != "replaced" {
+		t.Errorf("100.9.4 was not replaced by the override: %+v", v)
+	}
+	if sum := tools.PinnedSHA256("mongodb-database-tools-ubuntu2204-x86_64-100.13.0.tgz"); sum != "abcdef" {
+		t.Errorf("PinnedSHA256 = %q, want the lower-cased pin", sum)
+	}
+	if sum := tools.PinnedSHA256("unknown.tgz"); sum != "" {
+		t.Errorf("PinnedSHA256 of an unknown archive = %q", sum)
+	}
+	if _, err := LoadCatalog(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("a missing override is not an error: %v", err)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"100.12.0", "100.9.5", 1},
+		{"100.9.5", "100.12.0", -1},
+		{"100.12.2", "100.12.2", 0},
+		{"100.12", "100.12.0", 0},
+		{"100.12.1", "100.12", 1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
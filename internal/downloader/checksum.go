@@ -0,0 +1,106 @@
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// mongoToolsReleaseFeed lists every MongoDB Database Tools release with the checksums
+// of its archives.
+const mongoToolsReleaseFeed = "https://downloads.mongodb.org/tools/db/release.json"
+
+// feedClient fetches the release feed. The timeout keeps an unreachable or stalled
+// vendor host from hanging the installation.
+var feedClient = &http.Client{Timeout: 30 * time.Second}
+
+// FetchMongoToolsFeed downloads the release feed published by MongoDB and returns the
+// checksums of the archives of every version, by version and archive file name.
+func FetchMongoToolsFeed() (map[string]map[string]string, error) {
+	resp, err := feedClient.Get(mongoToolsReleaseFeed)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", mongoToolsReleaseFeed, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", mongoToolsReleaseFeed, resp.Status)
+	}
+
+	var feed struct {
+		Versions []struct {
+			Version   string `json:"version"`
+			Downloads []struct {
+				Archive struct {
+					URL    string `json:"url"`
+					SHA256 string `json:"sha256"`
+				} `json:"archive"`
+			} `json:"downloads"`
+		} `json:"versions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", mongoToolsReleaseFeed, err)
+	}
+	sums := make(map[string]map[string]string, len(feed.Versions))
+	for _, v := range feed.Versions {
+		for _, d := range v.Downloads {
+			if d.Archive.URL == "" || d.Archive.SHA256 == "" {
+				continue
+			}
+			if sums[v.Version] == nil {
+				sums[v.Version] = map[string]string{}
+			}
+			sums[v.Version][path.Base(d.Archive.URL)] = strings.ToLower(d.Archive.SHA256)
+		}
+	}
+	return sums, nil
+}
+
+// PublishedMongoToolsSHA256 looks up the checksum of the archive at url in the
+// release feed published by MongoDB. It is only meant for versions whose checksums
+// are not pinned in the catalog.
+func PublishedMongoToolsSHA256(url string) (string, error) {
+	sums, err := FetchMongoToolsFeed()
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(url)
+	for _, files := range sums {
+		if sum := files[name]; sum != "" {
+			return sum, nil
+		}
+	}
+	return "", fmt.Errorf("no published checksum for %s", url)
+}
+
+// FileSHA256 returns the hex encoded SHA-256 of the file at path.
+func FileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyFile compares the SHA-256 of the file at path with expected.
+func VerifyFile(path, expected string) error {
+	actual, err := FileSHA256(path)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", path, expected, actual)
+	}
+	return nil
+}
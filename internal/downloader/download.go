@@ -0,0 +1,51 @@
+// Package downloader fetches and unpacks the external tools used by the CLI.
+package downloader
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// DownloadFile saves the content at url to destPath. The data is written to
+// "<destPath>.part" first and only renamed once the transfer completed, so an
+// interrupted download never looks like a finished one. It returns the number of
+// bytes written.
+func DownloadFile(url, destPath string) (int64, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("download error: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("download of %s failed with status: %s", url, resp.Status)
+	}
+
+	partPath := destPath + ".part"
+	outFile, err := os.Create(partPath)
+	if err != nil {
+		return 0, fmt.Errorf("cannot create file %s: %w", partPath, err)
+	}
+	size, err := io.Copy(outFile, resp.Body)
+	if closeErr := outFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && resp.ContentLength >= 0 && size != resp.ContentLength {
+		err = fmt.Errorf("incomplete download: got %d of %d bytes", size, resp.ContentLength)
+	}
+	if err != nil {
+		os.Remove(partPath)
+		return 0, fmt.Errorf("error saving file: %w", err)
+	}
+	if err := os.Rename(partPath, destPath); err != nil {
+		os.Remove(partPath)
+		return 0, err
+	}
+	return size, nil
+}
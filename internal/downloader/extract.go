@@ -0,0 +1,154 @@
+package downloader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Extract unpacks a .zip, .tgz or .tar.gz archive into destination.
+func Extract(source, destination string) error {
+	lower := strings.ToLower(source)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return extractZip(source, destination)
+	case strings.HasSuffix(lower, ".tgz"), strings.HasSuffix(lower, ".tar.gz"):
+		return extractTarGz(source, destination)
+	}
+	return fmt.Errorf("unsupported archive format: %s", filepath.Base(source))
+}
+
+// safeJoin joins name to destination and rejects paths escaping it (Zip Slip).
+func safeJoin(destination, name string) (string, error) {
+	fpath := filepath.Join(destination, name)
+	if !strings.HasPrefix(fpath, filepath.Clean(destination)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("illegal file path: %s", fpath)
+	}
+	return fpath, nil
+}
+
+func extractZip(source, destination string) error {
+	reader, err := zip.OpenReader(source)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		fpath, err := safeJoin(destination, file.Name)
+		if err != nil {
+			return err
+		}
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(fpath, os.ModePerm); err != nil {
+				return err
+			}
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = writeFile(fpath, rc, file.Mode())
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTarGz(source, destination string) error {
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fpath, err := safeJoin(destination, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(fpath, os.ModePerm); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(fpath, tr, fs.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		default:
+			// لینک‌ها و سایر انواع فایل برای ابزارها لازم نیستند
+		}
+	}
+}
+
+func writeFile(fpath string, r io.Reader, mode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+		return err
+	}
+	// فایل‌های داخل پوشه bin باید قابل اجرا باشند، حتی اگر آرشیو mode را ثبت نکرده باشد
+	if filepath.Base(filepath.Dir(fpath)) == "bin" {
+		mode |= 0755
+	}
+	if mode.Perm() == 0 {
+		mode |= 0644
+	}
+	outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(outFile, r)
+	if closeErr := outFile.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// FindBinDir returns the directory below root that contains the given executable
+// (".exe" is appended on Windows).
+func FindBinDir(root, executable string) (string, error) {
+	if runtime.GOOS == "windows" {
+		executable += ".exe"
+	}
+	var found string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.EqualFold(d.Name(), executable) {
+			found = filepath.Dir(path)
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if found == "" {
+		return "", fmt.Errorf("could not find a directory containing '%s' in %s", executable, root)
+	}
+	return found, nil
+}
@@ -0,0 +1,171 @@
+package downloader
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const mongoToolsBaseURL = "https://fastdl.mongodb.org/tools/db/"
+
+// MongoToolsURL returns the download URL of the MongoDB Database Tools archive for the
+// given OS and architecture (runtime.GOOS/GOARCH values). If platform is set, e.g.
+// "ubuntu2204-x86_64", it is used instead of the detected one.
+func MongoToolsURL(version, goos, goarch, platform string) (string, error) {
+	if platform == "" {
+		detected, err := detectMongoPlatform(goos, goarch)
+		if err != nil {
+			return "", err
+		}
+		platform = detected
+	}
+	ext := ".tgz"
+	if strings.HasPrefix(platform, "windows") || strings.HasPrefix(platform, "macos") {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("%smongodb-database-tools-%s-%s%s", mongoToolsBaseURL, platform, version, ext), nil
+}
+
+// MongoToolsDirName returns the name of the directory a MongoDB Database Tools
+// archive extracts to, e.g. "mongodb-database-tools-ubuntu2204-x86_64-100.12.2".
+func MongoToolsDirName(url string) string {
+	name := url[strings.LastIndex(url, "/")+1:]
+	return strings.TrimSuffix(strings.TrimSuffix(name, ".zip"), ".tgz")
+}
+
+// InstalledMongoTools returns the versions found below toolsDir, mapped to their directories.
+func InstalledMongoTools(toolsDir string) (map[string]string, error) {
+	entries, err := os.ReadDir(toolsDir)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	installed := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || !strings.HasPrefix(name, "mongodb-database-tools-") {
+			continue
+		}
+		version := name[strings.LastIndex(name, "-")+1:]
+		installed[version] = filepath.Join(toolsDir, name)
+	}
+	return installed, nil
+}
+
+func detectMongoPlatform(goos, goarch string) (string, error) {
+	switch goos {
+	case "windows":
+		if goarch != "amd64" {
+			return "", fmt.Errorf("MongoDB Database Tools are only published for windows/amd64, not windows/%s", goarch)
+		}
+		return "windows-x86_64", nil
+	case "darwin":
+		switch goarch {
+		case "arm64":
+			return "macos-arm64", nil
+		case "amd64":
+			return "macos-x86_64", nil
+		}
+	case "linux":
+		if goarch != "amd64" && goarch != "arm64" {
+			break
+		}
+		id, versionID := readOSRelease("/etc/os-release")
+		return linuxPlatform(id, versionID, goarch), nil
+	}
+	return "", fmt.Errorf("MongoDB Database Tools are not published for %s/%s; set --platform or download them manually", goos, goarch)
+}
+
+// linuxPlatform maps an /etc/os-release ID and VERSION_ID to a MongoDB build name.
+// Unknown distributions fall back to the Ubuntu 22.04 build, which runs on most
+// glibc based systems.
+func linuxPlatform(id, versionID, goarch string) string {
+	major := majorVersion(versionID)
+	arch := "x86_64"
+	// بیلدهای arm در توزیع‌های مبتنی بر RHEL و Amazon با نام aarch64 منتشر می‌شوند
+	armName := "arm64"
+
+	var distro string
+	switch id {
+	case "ubuntu", "linuxmint", "pop":
+		switch {
+		case major >= 24:
+			distro = "ubuntu2404"
+		case major >= 22:
+			distro = "ubuntu2204"
+		case major >= 20:
+			distro = "ubuntu2004"
+		default:
+			distro = "ubuntu1804"
+		}
+	case "debian":
+		switch {
+		case major >= 12:
+			distro = "debian12"
+		case major == 11:
+			distro = "debian11"
+		default:
+			distro = "debian10"
+		}
+	case "rhel", "centos", "rocky", "almalinux", "ol", "fedora":
+		armName = "aarch64"
+		switch {
+		case id == "fedora" || major >= 9:
+			distro = "rhel93"
+		case major == 8:
+			distro = "rhel88"
+		default:
+			distro = "rhel70"
+		}
+	case "amzn":
+		armName = "aarch64"
+		if major >= 2023 {
+			distro = "amazon2023"
+		} else {
+			distro = "amazon2"
+		}
+	case "sles", "opensuse-leap":
+		distro = "suse15"
+	default:
+		distro = "ubuntu2204"
+	}
+	if goarch == "arm64" {
+		arch = armName
+	}
+	return distro + "-" + arch
+}
+
+// readOSRelease returns the ID and VERSION_ID fields of an os-release file.
+func readOSRelease(path string) (id, versionID string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		switch key {
+		case "ID":
+			id = strings.ToLower(value)
+		case "VERSION_ID":
+			versionID = value
+		}
+	}
+	return id, versionID
+}
+
+// majorVersion returns the leading number of a version such as "22.04".
+func majorVersion(v string) int {
+	major, _ := strconv.Atoi(strings.SplitN(v, ".", 2)[0])
+	return major
+}
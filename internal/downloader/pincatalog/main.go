@@ -0,0 +1,55 @@
+// Command pincatalog fills the sha256 maps of a tools catalog with the checksums
+// published by MongoDB, for every version the catalog lists. It is run by
+// 'go generate ./internal/downloader'; review the resulting diff before committing it.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/downloader"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: pincatalog <catalog.json>")
+	}
+	path := os.Args[1]
+
+	// 1. خواندن کاتالوگ فعلی
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var catalog downloader.Catalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		log.Fatalf("decoding %s: %v", path, err)
+	}
+
+	// 2. دریافت checksumها از فید انتشار MongoDB
+	sums, err := downloader.FetchMongoToolsFeed()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 3. پر کردن checksum همه‌ی آرشیوهای هر نسخه
+	for i, v := range catalog.MongoTools.Versions {
+		files := sums[v.Version]
+		if len(files) == 0 {
+			log.Fatalf("version %s is not in the release feed", v.Version)
+		}
+		catalog.MongoTools.Versions[i].SHA256 = files
+		fmt.Printf("%s: %d archives\n", v.Version, len(files))
+	}
+
+	out, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.TrimSpace(string(out))+"\n"), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
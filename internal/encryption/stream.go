@@ -0,0 +1,230 @@
+// Package encryption encrypts backup archives with a passphrase while they are
+// written, and decrypts them while they are read, without a plain text copy on disk.
+//
+// The stream starts with a header (magic, PBKDF2 iteration count, salt, nonce) followed
+// by AES-256-GCM sealed chunks of 64 KiB. Every chunk uses its own nonce and the final
+// chunk is marked, so reordered, truncated or extended archives are detected.
+package encryption
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/secrets"
+)
+
+const (
+	magic     = "DWENC\x00\x00\x01"
+	chunkSize = 64 * 1024
+	saltSize  = 16
+	nonceSize = 12
+
+	// maxIterations bounds the iteration count read from the header of an archive, so
+	// that a forged header cannot make decryption derive a key for hours.
+	maxIterations = 10 * secrets.KDFIterations
+)
+
+var (
+	// ErrNotEncrypted is returned by NewReader for streams without the encryption header.
+	ErrNotEncrypted = errors.New("archive is not encrypted")
+	// ErrDecrypt is returned when a chunk cannot be authenticated.
+	ErrDecrypt = errors.New("wrong passphrase, or the archive is corrupt or truncated")
+)
+
+type writer struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	nonce   [nonceSize]byte
+	counter uint64
+	buf     []byte
+	closed  bool
+}
+
+// NewWriter writes the encryption header to w and returns a writer that encrypts
+// everything written to it. Close must be called to write the final chunk; it does
+// not close w.
+func NewWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	if passphrase == "" {
+		return nil, errors.New("empty encryption passphrase")
+	}
+	ew := &writer{w: w, buf: make([]byte, 0, chunkSize)}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(ew.nonce[:]); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(passphrase, salt, secrets.KDFIterations)
+	if err != nil {
+		return nil, err
+	}
+	ew.aead = aead
+
+	header := make([]byte, 0, len(magic)+4+saltSize+nonceSize)
+	header = append(header, magic...)
+	header = binary.BigEndian.AppendUint32(header, secrets.KDFIterations)
+	header = append(header, salt...)
+	header = append(header, ew.nonce[:]...)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return ew, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed encryption writer")
+	}
+	n := len(p)
+	for len(p) > 0 {
+		// یک chunk پر فقط وقتی نوشته می‌شود که داده بیشتری برسد؛ آخرین chunk در Close علامت می‌خورد
+		if len(w.buf) == chunkSize {
+			if err := w.seal(false); err != nil {
+				return 0, err
+			}
+		}
+		m := copy(w.buf[len(w.buf):chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+m]
+		p = p[m:]
+	}
+	return n, nil
+}
+
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.seal(true)
+}
+
+func (w *writer) seal(last bool) error {
+	sealed := w.aead.Seal(nil, chunkNonce(w.nonce, w.counter), w.buf, chunkAAD(last))
+	w.counter++
+	w.buf = w.buf[:0]
+	_, err := w.w.Write(sealed)
+	return err
+}
+
+type reader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	nonce   [nonceSize]byte
+	counter uint64
+	chunk   []byte
+	plain   []byte
+	done    bool
+}
+
+// NewReader reads the encryption header from r and returns a reader of the decrypted
+// stream. It returns ErrNotEncrypted if r does not start with the header, and ErrDecrypt
+// if the first chunk cannot be decrypted with the passphrase.
+func NewReader(r io.Reader, passphrase string) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(magic)+4+saltSize+nonceSize)
+	if _, err := io.ReadFull(br, header); err != nil || string(header[:len(magic)]) != magic {
+		return nil, ErrNotEncrypted
+	}
+	iterations := int(binary.BigEndian.Uint32(header[len(magic):]))
+	salt := header[len(magic)+4 : len(magic)+4+saltSize]
+	aead, err := newAEAD(passphrase, salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+	er := &reader{r: br, aead: aead, chunk: make([]byte, chunkSize+aead.Overhead())}
+	copy(er.nonce[:], header[len(magic)+4+saltSize:])
+	// اولین chunk همین‌جا باز می‌شود تا رمز اشتباه قبل از اجرای ابزار بازیابی مشخص شود
+	if err := er.next(); err != nil {
+		return nil, err
+	}
+	return er, nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// next decrypts the following chunk. A chunk is the last one if the stream ends
+// after it; it must then have been sealed as the final chunk.
+func (r *reader) next() error {
+	n, err := io.ReadFull(r.r, r.chunk)
+	last := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		last = true
+	case err != nil:
+		return err
+	default:
+		if _, err := r.r.Peek(1); err == io.EOF {
+			last = true
+		}
+	}
+	plain, err := r.aead.Open(r.chunk[:0], chunkNonce(r.nonce, r.counter), r.chunk[:n], chunkAAD(last))
+	if err != nil {
+		return ErrDecrypt
+	}
+	r.counter++
+	r.plain = plain
+	r.done = last
+	return nil
+}
+
+// IsEncrypted reports whether r starts with the encryption header. It consumes the
+// bytes it reads.
+func IsEncrypted(r io.Reader) bool {
+	head := make([]byte, len(magic))
+	_, err := io.ReadFull(r, head)
+	return err == nil && string(head) == magic
+}
+
+func newAEAD(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations <= 0 {
+		return nil, fmt.Errorf("invalid key derivation iteration count %d", iterations)
+	}
+	if iterations > maxIterations {
+		return nil, fmt.Errorf("key derivation iteration count %d exceeds the maximum of %d; the archive header is corrupt or was not written by this tool", iterations, maxIterations)
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce XORs the chunk counter into the last 8 bytes of the base nonce.
+func chunkNonce(base [nonceSize]byte, counter uint64) []byte {
+	nonce := base
+	c := binary.BigEndian.Uint64(nonce[4:])
+	binary.BigEndian.PutUint64(nonce[4:], c^counter)
+	return nonce[:]
+}
+
+func chunkAAD(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
@@ -0,0 +1,144 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+const passphrase = "correct horse battery staple"
+
+// headerSize is the length of the encryption header and sealedSize the length of a
+// full sealed chunk.
+const (
+	headerSize = len(magic) + 4 + saltSize + nonceSize
+	sealedSize = chunkSize + 16
+)
+
+func encrypt(t *testing.T, plain []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Writes of odd sizes cross the chunk boundaries.
+	for p := plain; len(p) > 0; {
+		n := min(len(p), 10000)
+		if _, err := w.Write(p[:n]); err != nil {
+			t.Fatal(err)
+		}
+		p = p[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// decrypt returns the plain text of an encrypted stream, or an error if NewReader or
+// any Read fails.
+func decrypt(data []byte, passphrase string) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(data), passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 123} {
+		plain := randomBytes(t, size)
+		got, err := decrypt(encrypt(t, plain), passphrase)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Fatalf("size %d: decrypted %d bytes that differ from the plain text", size, len(got))
+		}
+	}
+}
+
+func TestTampered(t *testing.T) {
+	plain := randomBytes(t, 3*chunkSize+123)
+	data := encrypt(t, plain)
+	chunk := func(i int) []byte {
+		start := headerSize + i*sealedSize
+		return data[start:min(start+sealedSize, len(data))]
+	}
+	join := func(parts ...[]byte) []byte {
+		return bytes.Join(append([][]byte{data[:headerSize]}, parts...), nil)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"last chunk missing", data[:headerSize+3*sealedSize]},
+		{"truncated inside a chunk", data[:len(data)-10]},
+		{"only the header", data[:headerSize]},
+		{"chunks reordered", join(chunk(1), chunk(0), chunk(2), chunk(3))},
+		{"chunk repeated", join(chunk(0), chunk(0), chunk(1), chunk(2), chunk(3))},
+		{"chunk dropped", join(chunk(0), chunk(2), chunk(3))},
+		{"extended after the last chunk", append(bytes.Clone(data), chunk(1)...)},
+		{"bit flipped", func() []byte {
+			d := bytes.Clone(data)
+			d[headerSize+sealedSize+100] ^= 1
+			return d
+		}()},
+		{"salt changed", func() []byte {
+			d := bytes.Clone(data)
+			d[len(magic)+4] ^= 1
+			return d
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decrypt(tt.data, passphrase)
+			if err == nil {
+				t.Fatalf("decrypted %d bytes without an error", len(got))
+			}
+			if !errors.Is(err, ErrDecrypt) {
+				t.Errorf("error = %v, want ErrDecrypt", err)
+			}
+		})
+	}
+}
+
+func TestWrongPassphrase(t *testing.T) {
+	data := encrypt(t, []byte("some archive"))
+	got, err := decrypt(data, "wrong")
+	if !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("decrypt = %q, %v; want ErrDecrypt", got, err)
+	}
+}
+
+func TestNotEncrypted(t *testing.T) {
+	if _, err := NewReader(strings.NewReader("\x1f\x8bplain gzip data"), passphrase); !errors.Is(err, ErrNotEncrypted) {
+		t.Fatalf("NewReader = %v, want ErrNotEncrypted", err)
+	}
+}
+
+func TestIterationBounds(t *testing.T) {
+	data := encrypt(t, []byte("some archive"))
+	for _, iterations := range []uint32{0, maxIterations + 1, 1<<32 - 1} {
+		d := bytes.Clone(data)
+		binary.BigEndian.PutUint32(d[len(magic):], iterations)
+		if _, err := decrypt(d, passphrase); err == nil || !strings.Contains(err.Error(), "iteration count") {
+			t.Errorf("iterations %d: error = %v, want an iteration count error", iterations, err)
+		}
+	}
+}
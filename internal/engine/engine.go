@@ -0,0 +1,115 @@
+// Package engine defines the interface every supported database system implements,
+// so the backup and restore commands can share archive naming, manifests and retention.
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/redact"
+)
+
+// Engine wraps the command-line dump and restore tools of one database system.
+type Engine interface {
+	// Name identifies the engine in manifests and messages, e.g. "mongodb".
+	Name() string
+	// Extension is the file extension of the archives produced by Backup, e.g. ".gz".
+	Extension() string
+	// CheckTools verifies that the dump and restore tools are available.
+	CheckTools() error
+	// Backup dumps the database behind opts.URI and writes the archive to opts.Archive.
+	Backup(ctx context.Context, opts BackupOptions) (*BackupResult, error)
+	// Restore loads the archive read from opts.Archive into the database behind opts.URI.
+	Restore(ctx context.Context, opts RestoreOptions) error
+	// ListContents returns the namespaces (collections, tables...) stored in an archive.
+	ListContents(ctx context.Context, archive io.Reader) ([]string, error)
+}
+
+// ErrConnection is wrapped by the errors of Backup and Restore when the tool failed
+// because the database server could not be reached.
+var ErrConnection = errors.New("cannot connect to the database server")
+
+// BackupOptions are the engine independent inputs of a backup.
+type BackupOptions struct {
+	URI string
+	// Archive receives the archive stream. The caller creates the file, so it can be
+	// encrypted on the way, and closes it.
+	Archive io.Writer
+	// Output receives the progress output of the dump tool.
+	Output io.Writer
+}
+
+// BackupResult describes what a backup produced, for the manifest.
+type BackupResult struct {
+	ToolVersion        string
+	Namespaces         []string
+	ExcludedNamespaces []string
+}
+
+// RestoreOptions are the engine independent inputs of a restore.
+type RestoreOptions struct {
+	URI string
+	// Archive is the archive stream, already decrypted.
+	Archive io.Reader
+	// Drop removes existing objects in the target before restoring them.
+	Drop bool
+	// Output receives the progress output of the restore tool.
+	Output io.Writer
+}
+
+// Renamer is implemented by engines that can restore only part of an archive or
+// restore objects under other names.
+type Renamer interface {
+	// RestoredName returns the name an object listed by ListContents is restored
+	// as, and false if it is not restored.
+	RestoredName(name string) (string, bool)
+}
+
+// FindTool locates an executable. If dir is set, the tool must exist in that directory;
+// otherwise it is looked up on PATH. The ".exe" suffix is added on Windows.
+func FindTool(dir, name string) (string, error) {
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	if dir == "" {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return "", fmt.Errorf("%s not found on PATH", name)
+		}
+		return path, nil
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("%s not found at the specified path: %s", name, path)
+	}
+	return path, nil
+}
+
+// SourceHost returns the host part of a connection string without credentials,
+// database or options, e.g. "db1.example.com:27017,db2.example.com:27017".
+func SourceHost(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.Host != "" {
+		return u.Host
+	}
+	// رشته‌های چند-میزبانه همیشه توسط net/url پشتیبانی نمی‌شوند
+	rest := uri
+	if i := strings.Index(rest, "://"); i >= 0 {
+		rest = rest[i+3:]
+	}
+	if i := strings.IndexAny(rest, "/?"); i >= 0 {
+		rest = rest[:i]
+	}
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		rest = rest[i+1:]
+	}
+	// رشته‌های کلید=مقدار (مثل "host=db password=...") را هم پوشش می‌دهیم
+	return redact.String(rest)
+}
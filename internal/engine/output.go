@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/redact"
+)
+
+// LineWriter prefixes every line written to it, e.g. "  [mongodump]: ...", and masks
+// credentials in it. It is safe to share between the stdout and stderr of a process.
+type LineWriter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    bytes.Buffer
+	// connectionFailed is set once a line reports that the server was unreachable.
+	connectionFailed bool
+}
+
+// connectionFailures are (lower case) messages of mongodump, pg_dump, mysqldump and
+// their restore counterparts that mean the server could not be reached.
+var connectionFailures = []string{
+	"connection refused",
+	"server selection error",
+	"no reachable servers",
+	"could not connect to server",
+	"could not translate host name",
+	"no such host",
+	"can't connect to mysql server",
+	"unknown mysql server host",
+	"network is unreachable",
+	"connection timed out",
+	"i/o timeout",
+}
+
+// NewLineWriter returns a LineWriter printing to out with the given prefix.
+func NewLineWriter(out io.Writer, prefix string) *LineWriter {
+	return &LineWriter{out: out, prefix: prefix}
+}
+
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// خط ناقص را برای نوشتن بعدی نگه می‌داریم
+			w.buf.WriteString(line)
+			break
+		}
+		w.scan(line)
+		fmt.Fprintf(w.out, "%s%s", w.prefix, redact.String(line))
+	}
+	return len(p), nil
+}
+
+func (w *LineWriter) scan(line string) {
+	lower := strings.ToLower(line)
+	for _, msg := range connectionFailures {
+		if strings.Contains(lower, msg) {
+			w.connectionFailed = true
+			return
+		}
+	}
+}
+
+// ToolError returns the error of a failed tool run. If the tool output written to log
+// reported an unreachable server, the error wraps ErrConnection.
+func ToolError(name string, err error, log *LineWriter) error {
+	log.mu.Lock()
+	if log.buf.Len() > 0 {
+		log.scan(log.buf.String())
+	}
+	failed := log.connectionFailed
+	log.mu.Unlock()
+	if failed {
+		return fmt.Errorf("%s failed: %w: %w", name, ErrConnection, err)
+	}
+	return fmt.Errorf("%s failed: %w", name, err)
+}
+
+// Flush prints a trailing line that did not end with a newline.
+func (w *LineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.buf.Len() > 0 {
+		fmt.Fprintf(w.out, "%s%s\n", w.prefix, redact.String(w.buf.String()))
+		w.buf.Reset()
+	}
+}
+
+// stopGracePeriod is how long a tool may take to exit after it was interrupted
+// before it is killed.
+const stopGracePeriod = 10 * time.Second
+
+// Command is exec.CommandContext for the dump and restore tools. When ctx is done the
+// tool is interrupted (like Ctrl+C) so it can stop cleanly, and killed if it has not
+// exited after a grace period.
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	c := exec.CommandContext(ctx, name, args...)
+	if runtime.GOOS != "windows" {
+		// ویندوز ارسال سیگنال interrupt را پشتیبانی نمی‌کند و پردازه مستقیم kill می‌شود
+		c.Cancel = func() error { return c.Process.Signal(os.Interrupt) }
+	}
+	c.WaitDelay = stopGracePeriod
+	return c
+}
+
+// RunTool runs a command-line tool, streaming its combined output to out with a
+// "[tool]" prefix. The process is killed when ctx is cancelled.
+func RunTool(ctx context.Context, toolPath string, args []string, out io.Writer) error {
+	return RunToolStream(ctx, toolPath, args, nil, nil, out)
+}
+
+// RunToolStream is RunTool for tools that read an archive from stdin or write one to
+// stdout. If stdout is nil, the standard output is treated as progress output.
+func RunToolStream(ctx context.Context, toolPath string, args []string, stdin io.Reader, stdout io.Writer, out io.Writer) error {
+	name := strings.TrimSuffix(filepath.Base(toolPath), ".exe")
+	if out == nil {
+		out = io.Discard
+	}
+	w := NewLineWriter(out, fmt.Sprintf("  [%s]: ", name))
+	defer w.Flush()
+
+	c := Command(ctx, toolPath, args...)
+	c.Stdin = stdin
+	c.Stdout = w
+	if stdout != nil {
+		c.Stdout = stdout
+	}
+	c.Stderr = w
+	if err := c.Run(); err != nil {
+		return ToolError(name, err, w)
+	}
+	return nil
+}
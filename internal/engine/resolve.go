@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ToolCandidate is a directory a resolver considered for a set of tools.
+type ToolCandidate struct {
+	// Source describes where the directory came from, e.g. "paths.mongo_tools" or "PATH".
+	Source string
+	Dir    string
+	// Err explains why the candidate was rejected; nil if it has every tool.
+	Err error
+}
+
+// ResolveToolDir checks every candidate in order and returns the first directory that
+// contains all of the named tools, together with the checked candidates. Candidates
+// with Err already set are passed through unchecked.
+func ResolveToolDir(tools []string, candidates []ToolCandidate) (string, []ToolCandidate, error) {
+	var dir string
+	chain := make([]ToolCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.Err == nil && c.Dir == "" {
+			c.Err = errors.New("not found")
+		}
+		if c.Err == nil {
+			for _, tool := range tools {
+				if _, err := FindTool(c.Dir, tool); err != nil {
+					c.Err = err
+					break
+				}
+			}
+		}
+		if c.Err == nil && dir == "" {
+			dir = c.Dir
+		}
+		chain = append(chain, c)
+	}
+	if dir == "" {
+		return "", chain, fmt.Errorf("%s not found in any of the %d searched locations", strings.Join(tools, "/"), len(chain))
+	}
+	return dir, chain, nil
+}
+
+// LookPathDir returns the directory on PATH that contains the tool, or "".
+func LookPathDir(name string) string {
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return ""
+	}
+	return filepath.Dir(path)
+}
@@ -0,0 +1,120 @@
+package mongodb
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// archiveMagic is the magic number at the start of every mongodump --archive stream.
+const archiveMagic = 0x8199e26d
+
+// ArchiveCollection is a namespace listed in the prelude of a mongodump archive.
+type ArchiveCollection struct {
+	DB         string
+	Collection string
+	// Metadata is the extended JSON written by mongodump, with options and indexes.
+	Metadata string
+}
+
+// Namespace returns the "db.collection" name of the entry.
+func (c ArchiveCollection) Namespace() string {
+	if c.DB == "" {
+		return c.Collection
+	}
+	return c.DB + "." + c.Collection
+}
+
+// NewArchiveReader decompresses a gzip compressed mongodump archive stream.
+func NewArchiveReader(r io.Reader) (io.ReadCloser, error) {
+	gz, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("not a gzip compressed archive: %w", err)
+	}
+	return gz, nil
+}
+
+// ReadPrelude reads the archive header and returns the namespaces it contains.
+func ReadPrelude(r io.Reader) ([]ArchiveCollection, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, fmt.Errorf("reading archive header: %w", err)
+	}
+	if binary.LittleEndian.Uint32(magic[:]) != archiveMagic {
+		return nil, fmt.Errorf("not a mongodump archive (bad magic number)")
+	}
+	// سند اول، مشخصات کلی آرشیو (نسخه ابزار و سرور) است
+	if _, err := readBSON(r); err != nil {
+		return nil, fmt.Errorf("reading archive prelude: %w", err)
+	}
+
+	var collections []ArchiveCollection
+	for {
+		doc, err := readBSON(r)
+		if err == errTerminator {
+			return collections, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading archive prelude: %w", err)
+		}
+		collections = append(collections, ArchiveCollection{
+			DB:         doc.str("db"),
+			Collection: doc.str("collection"),
+			Metadata:   doc.str("metadata"),
+		})
+	}
+}
+
+// readArchive calls fn with every document of a decompressed mongodump archive and
+// the namespace it belongs to, in archive order. Like mongorestore, it fails if the
+// archive ends before the EOF marker of every namespace of the prelude.
+func readArchive(r io.Reader, fn func(db, collection string, doc bsonDoc) error) error {
+	collections, err := ReadPrelude(r)
+	if err != nil {
+		return err
+	}
+	unfinished := make(map[string]bool, len(collections))
+	for _, c := range collections {
+		unfinished[c.Namespace()] = true
+	}
+	// بدنه آرشیو: هدر namespace، سندها و سپس terminator
+	for {
+		header, err := readBSON(r)
+		if err == io.EOF {
+			for _, c := range collections {
+				if unfinished[c.Namespace()] {
+					return fmt.Errorf("reading archive: %s ends early: %w", c.Namespace(), io.ErrUnexpectedEOF)
+				}
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive: %w", err)
+		}
+		db, collection := header.str("db"), header.str("collection")
+		eof := header.boolean("EOF")
+		if eof {
+			delete(unfinished, ArchiveCollection{DB: db, Collection: collection}.Namespace())
+		}
+		for {
+			doc, err := readBSON(r)
+			if err == errTerminator {
+				break
+			}
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return fmt.Errorf("reading archive: %w", err)
+			}
+			if eof {
+				continue
+			}
+			if err := fn(db, collection, doc); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package mongodb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// bsonOf encodes a BSON document from key/value pairs; values are strings or bools.
+func bsonOf(pairs ...any) []byte {
+	var body bytes.Buffer
+	for i := 0; i < len(pairs); i += 2 {
+		key := pairs[i].(string)
+		switch v := pairs[i+1].(type) {
+		case string:
+			body.WriteByte(0x02)
+			body.WriteString(key + "\x00")
+			binary.Write(&body, binary.LittleEndian, int32(len(v)+1))
+			body.WriteString(v + "\x00")
+		case bool:
+			body.WriteByte(0x08)
+			body.WriteString(key + "\x00")
+			if v {
+				body.WriteByte(1)
+			} else {
+				body.WriteByte(0)
+			}
+		}
+	}
+	doc := binary.LittleEndian.AppendUint32(nil, uint32(body.Len()+5))
+	doc = append(doc, body.Bytes()...)
+	return append(doc, 0)
+}
+
+// testArchive returns a decompressed mongodump archive with app.users (two documents)
+// and app.orders (one document).
+func testArchive() []byte {
+	terminator := []byte{0xff, 0xff, 0xff, 0xff}
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, uint32(archiveMagic))
+	b.Write(bsonOf("server_version", "7.0.0", "tool_version", "100.12.2"))
+	b.Write(bsonOf("db", "app", "collection", "users", "metadata", `{"indexes":[]}`))
+	b.Write(bsonOf("db", "app", "collection", "orders", "metadata", `{"indexes":[]}`))
+	b.Write(terminator)
+	b.Write(bsonOf("db", "app", "collection", "users"))
+	b.Write(bsonOf("name", "ali"))
+	b.Write(bsonOf("name", "sara"))
+	b.Write(terminator)
+	b.Write(bsonOf("db", "app", "collection", "orders"))
+	b.Write(bsonOf("item", "book"))
+	b.Write(terminator)
+	b.Write(bsonOf("db", "app", "collection", "users", "EOF", true))
+	b.Write(terminator)
+	b.Write(bsonOf("db", "app", "collection", "orders", "EOF", true))
+	b.Write(terminator)
+	return b.Bytes()
+}
+
+func countDocuments(archive []byte) (map[string]int, error) {
+	counts := map[string]int{}
+	err := readArchive(bytes.NewReader(archive), func(db, collection string, doc bsonDoc) error {
+		counts[db+"."+collection]++
+		return nil
+	})
+	return counts, err
+}
+
+func TestReadArchive(t *testing.T) {
+	archive := testArchive()
+	collections, err := ReadPrelude(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var namespaces []string
+	for _, c := range collections {
+		namespaces = append(namespaces, c.Namespace())
+	}
+	if want := []string{"app.users", "app.orders"}; !reflect.DeepEqual(namespaces, want) {
+		t.Errorf("ReadPrelude namespaces = %v, want %v", namespaces, want)
+	}
+
+	counts, err := countDocuments(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"app.users": 2, "app.orders": 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("documents = %v, want %v", counts, want)
+	}
+}
+
+func TestReadArchiveTruncated(t *testing.T) {
+	archive := testArchive()
+	// آرشیوی که در هر نقطه‌ای بریده شده باشد، حتی در مرز سندها، باید خطا بدهد
+	for n := 0; n < len(archive); n++ {
+		if _, err := countDocuments(archive[:n]); err == nil {
+			t.Fatalf("archive cut at %d of %d bytes read without an error", n, len(archive))
+		} else if n > 4 && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			t.Fatalf("archive cut at %d bytes: %v, want an unexpected EOF", n, err)
+		}
+	}
+}
+
+func TestReadBSONSize(t *testing.T) {
+	tests := []struct {
+		name string
+		size uint32
+		want string // "" if valid
+	}{
+		{"smallest document", 5, ""},
+		{"too small", 4, "invalid BSON document size 4"},
+		{"negative", 0xfffffff0, "invalid BSON document size -16"},
+		{"largest document", maxBSONSize, ""},
+		{"one byte too large", maxBSONSize + 1, "invalid BSON document size"},
+		{"2 GiB", 1<<31 - 1, "invalid BSON document size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := binary.LittleEndian.AppendUint32(nil, tt.size)
+			if tt.want == "" {
+				data = append(data, make([]byte, tt.size-4)...)
+			}
+			doc, err := readBSON(bytes.NewReader(data))
+			switch {
+			case tt.want == "" && (err != nil || len(doc) != int(tt.size)):
+				t.Fatalf("readBSON = %d bytes, %v; want %d bytes", len(doc), err, tt.size)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Fatalf("readBSON = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+
+	// طول بزرگ در آرشیو پیش از خواندن بدنه رد می‌شود
+	archive := testArchive()
+	prelude := 4 + len(bsonOf("server_version", "7.0.0", "tool_version", "100.12.2"))
+	binary.LittleEndian.PutUint32(archive[prelude:], 1<<30)
+	if _, err := ReadPrelude(bytes.NewReader(archive)); err == nil || !strings.Contains(err.Error(), "invalid BSON document size") {
+		t.Errorf("ReadPrelude with an oversized document = %v, want an invalid size error", err)
+	}
+}
@@ -0,0 +1,139 @@
+package mongodb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// errTerminator is returned by readBSON when it reads the archive terminator (-1)
+// instead of a document.
+var errTerminator = errors.New("archive terminator")
+
+// maxBSONSize is the largest document size readBSON accepts: the 16 MiB limit of
+// MongoDB documents plus the headroom the server allows for internal documents such
+// as oplog entries. Larger sizes come from corrupt archives.
+const maxBSONSize = 16*1024*1024 + 16*1024
+
+// bsonDoc is a raw BSON document. Only the few lookups needed to read
+// mongodump archives are implemented.
+type bsonDoc []byte
+
+// readBSON reads one length-prefixed BSON document from r.
+func readBSON(r io.Reader) (bsonDoc, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := int32(binary.LittleEndian.Uint32(header[:]))
+	if size == -1 {
+		return nil, errTerminator
+	}
+	if size < 5 || size > maxBSONSize {
+		return nil, fmt.Errorf("invalid BSON document size %d", size)
+	}
+	doc := make([]byte, size)
+	copy(doc, header[:])
+	if _, err := io.ReadFull(r, doc[4:]); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// lookup returns the type and raw value of a top-level element.
+func (d bsonDoc) lookup(key string) (byte, []byte, bool) {
+	pos := 4
+	for pos < len(d)-1 {
+		typ := d[pos]
+		pos++
+		nameEnd := pos
+		for nameEnd < len(d) && d[nameEnd] != 0 {
+			nameEnd++
+		}
+		if nameEnd >= len(d) {
+			return 0, nil, false
+		}
+		name := string(d[pos:nameEnd])
+		pos = nameEnd + 1
+		size, ok := d.valueSize(typ, pos)
+		if !ok || pos+size > len(d) {
+			return 0, nil, false
+		}
+		if name == key {
+			return typ, d[pos : pos+size], true
+		}
+		pos += size
+	}
+	return 0, nil, false
+}
+
+// valueSize returns the encoded size of a value of the given type starting at pos.
+func (d bsonDoc) valueSize(typ byte, pos int) (int, bool) {
+	int32At := func(p int) (int, bool) {
+		if p+4 > len(d) {
+			return 0, false
+		}
+		return int(int32(binary.LittleEndian.Uint32(d[p:]))), true
+	}
+	switch typ {
+	case 0x06, 0x0A, 0x7F, 0xFF: // undefined, null, max key, min key
+		return 0, true
+	case 0x08: // bool
+		return 1, true
+	case 0x10: // int32
+		return 4, true
+	case 0x01, 0x09, 0x11, 0x12: // double, datetime, timestamp, int64
+		return 8, true
+	case 0x07: // ObjectId
+		return 12, true
+	case 0x13: // decimal128
+		return 16, true
+	case 0x02, 0x0D, 0x0E: // string, JavaScript, symbol
+		n, ok := int32At(pos)
+		return 4 + n, ok
+	case 0x03, 0x04, 0x0F: // document, array, code with scope
+		return int32At(pos)
+	case 0x05: // binary
+		n, ok := int32At(pos)
+		return 5 + n, ok
+	case 0x0C: // DBPointer
+		n, ok := int32At(pos)
+		return 4 + n + 12, ok
+	case 0x0B: // regex: two cstrings
+		end := pos
+		for i := 0; i < 2; i++ {
+			for end < len(d) && d[end] != 0 {
+				end++
+			}
+			end++
+		}
+		return end - pos, end <= len(d)
+	}
+	return 0, false
+}
+
+// str returns a string element, or "" if it is missing or not a string.
+func (d bsonDoc) str(key string) string {
+	typ, val, ok := d.lookup(key)
+	if !ok || typ != 0x02 || len(val) < 5 {
+		return ""
+	}
+	return string(val[4 : len(val)-1])
+}
+
+// boolean returns a bool element, or false if it is missing or not a bool.
+func (d bsonDoc) boolean(key string) bool {
+	typ, val, ok := d.lookup(key)
+	return ok && typ == 0x08 && val[0] == 1
+}
+
+// timestamp returns a BSON timestamp element.
+func (d bsonDoc) timestamp(key string) (Timestamp, bool) {
+	typ, val, ok := d.lookup(key)
+	if !ok || typ != 0x11 {
+		return Timestamp{}, false
+	}
+	// ۳۲ بیت پایین شماره ترتیبی و ۳۲ بیت بالا ثانیه است
+	return Timestamp{T: binary.LittleEndian.Uint32(val[4:]), I: binary.LittleEndian.Uint32(val[:4])}, true
+}
@@ -0,0 +1,117 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+)
+
+// CloneOptions configure a direct source-to-target copy.
+type CloneOptions struct {
+	SourceURI string
+	TargetURI string
+	// NSFrom and NSTo are pairs of mongorestore --nsFrom/--nsTo patterns, e.g. "app.*" -> "app_copy.*".
+	NSFrom []string
+	NSTo   []string
+	// Drop drops the target collections before restoring them.
+	Drop bool
+	// Copy, if set, receives the gzip compressed archive stream, e.g. to keep a backup on disk.
+	Copy io.Writer
+	// Output receives the progress output of both tools.
+	Output io.Writer
+}
+
+// Clone pipes the archive written by mongodump straight into mongorestore,
+// without an intermediate file.
+func (e *Engine) Clone(ctx context.Context, opts CloneOptions) (*engine.BackupResult, error) {
+	if err := e.Selection.Validate(); err != nil {
+		return nil, err
+	}
+	if len(opts.NSFrom) != len(opts.NSTo) {
+		return nil, errors.New("every --ns-from needs a matching --ns-to")
+	}
+	mongoDumpPath, err := engine.FindTool(e.ToolsPath, "mongodump")
+	if err != nil {
+		return nil, err
+	}
+	mongoRestorePath, err := engine.FindTool(e.ToolsPath, "mongorestore")
+	if err != nil {
+		return nil, err
+	}
+
+	dumpArgs := []string{fmt.Sprintf("--uri=%s", opts.SourceURI), "--archive", "--gzip"}
+	dumpArgs = append(dumpArgs, e.Selection.Args()...)
+
+	restoreArgs := []string{fmt.Sprintf("--uri=%s", opts.TargetURI), "--archive", "--gzip"}
+	if opts.Drop {
+		restoreArgs = append(restoreArgs, "--drop")
+	}
+	for i := range opts.NSFrom {
+		restoreArgs = append(restoreArgs, fmt.Sprintf("--nsFrom=%s", opts.NSFrom[i]), fmt.Sprintf("--nsTo=%s", opts.NSTo[i]))
+	}
+
+	out := opts.Output
+	if out == nil {
+		out = io.Discard
+	}
+	dumpLog := engine.NewLineWriter(out, "  [mongodump]: ")
+	restoreLog := engine.NewLineWriter(out, "  [mongorestore]: ")
+	defer dumpLog.Flush()
+	defer restoreLog.Flush()
+
+	// خروجی mongodump مستقیماً ورودی mongorestore است (و در صورت نیاز یک کپی روی دیسک)
+	pr, pw := io.Pipe()
+	var archiveOut io.Writer = pw
+	if opts.Copy != nil {
+		archiveOut = io.MultiWriter(pw, opts.Copy)
+	}
+
+	dump := engine.Command(ctx, mongoDumpPath, dumpArgs...)
+	dump.Stdout = archiveOut
+	dump.Stderr = dumpLog
+
+	restore := engine.Command(ctx, mongoRestorePath, restoreArgs...)
+	restore.Stdin = pr
+	restore.Stdout = restoreLog
+	restore.Stderr = restoreLog
+
+	if err := restore.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start mongorestore: %w", err)
+	}
+	if err := dump.Start(); err != nil {
+		pw.Close()
+		restore.Wait()
+		return nil, fmt.Errorf("failed to start mongodump: %w", err)
+	}
+
+	dumpDone := make(chan error, 1)
+	go func() {
+		err := dump.Wait()
+		// بستن pipe به mongorestore پایان آرشیو را اعلام می‌کند
+		pw.CloseWithError(err)
+		dumpDone <- err
+	}()
+
+	restoreErr := restore.Wait()
+	// اگر mongorestore زودتر متوقف شود، mongodump نباید روی pipe قفل بماند
+	pr.CloseWithError(errors.New("mongorestore exited"))
+	dumpErr := <-dumpDone
+
+	switch {
+	case restoreErr != nil && dumpErr != nil:
+		return nil, fmt.Errorf("%w (%w)", engine.ToolError("mongorestore", restoreErr, restoreLog), engine.ToolError("mongodump", dumpErr, dumpLog))
+	case restoreErr != nil:
+		return nil, engine.ToolError("mongorestore", restoreErr, restoreLog)
+	case dumpErr != nil:
+		return nil, engine.ToolError("mongodump", dumpErr, dumpLog)
+	}
+
+	return &engine.BackupResult{
+		ToolVersion:        ToolVersion(mongoDumpPath),
+		Namespaces:         e.Selection.Namespaces(),
+		ExcludedNamespaces: e.Selection.ExcludedNamespaces(),
+	}, nil
+}
@@ -0,0 +1,143 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+)
+
+// Engine implements engine.Engine with mongodump and mongorestore.
+type Engine struct {
+	// ToolsPath is the directory containing mongodump and mongorestore.
+	ToolsPath string
+	// Selection narrows down what Backup dumps.
+	Selection Selection
+	// Oplog makes Backup include the oplog entries written during the dump
+	// (mongodump --oplog, replica sets only) and Restore replay them.
+	Oplog bool
+	// Remap selects and renames the namespaces Restore restores.
+	Remap Remap
+}
+
+var (
+	_ engine.Engine  = (*Engine)(nil)
+	_ engine.Renamer = (*Engine)(nil)
+)
+
+func (e *Engine) Name() string      { return "mongodb" }
+func (e *Engine) Extension() string { return ".gz" }
+
+func (e *Engine) CheckTools() error {
+	for _, tool := range []string{"mongodump", "mongorestore"} {
+		if _, err := engine.FindTool(e.ToolsPath, tool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Engine) Backup(ctx context.Context, opts engine.BackupOptions) (*engine.BackupResult, error) {
+	if err := e.Selection.Validate(); err != nil {
+		return nil, err
+	}
+	if e.Oplog && e.Selection.Database != "" {
+		return nil, errors.New("--oplog requires a dump of the whole deployment (no --db)")
+	}
+	mongoDumpPath, err := engine.FindTool(e.ToolsPath, "mongodump")
+	if err != nil {
+		return nil, err
+	}
+
+	// --archive بدون مقدار، آرشیو را روی stdout می‌نویسد
+	args := []string{
+		fmt.Sprintf("--uri=%s", opts.URI),
+		"--archive",
+		"--gzip",
+	}
+	args = append(args, e.Selection.Args()...)
+	if e.Oplog {
+		args = append(args, "--oplog")
+	}
+	if err := engine.RunToolStream(ctx, mongoDumpPath, args, nil, opts.Archive, opts.Output); err != nil {
+		return nil, err
+	}
+
+	return &engine.BackupResult{
+		ToolVersion:        ToolVersion(mongoDumpPath),
+		Namespaces:         e.Selection.Namespaces(),
+		ExcludedNamespaces: e.Selection.ExcludedNamespaces(),
+	}, nil
+}
+
+func (e *Engine) Restore(ctx context.Context, opts engine.RestoreOptions) error {
+	if err := e.Remap.Validate(); err != nil {
+		return err
+	}
+	mongoRestorePath, err := engine.FindTool(e.ToolsPath, "mongorestore")
+	if err != nil {
+		return err
+	}
+	remap, archive := e.Remap, opts.Archive
+	if remap.TargetDB != "" {
+		// پایگاه داده مبدأ از ابتدای آرشیو خوانده می‌شود و آرشیو کامل به mongorestore می‌رسد
+		collections, rest, err := peekPrelude(opts.Archive)
+		if err != nil {
+			return err
+		}
+		if remap, err = remap.Resolve(namespaces(collections)); err != nil {
+			return err
+		}
+		archive = rest
+	}
+	args := []string{
+		fmt.Sprintf("--uri=%s", opts.URI),
+		"--archive",
+		"--gzip",
+	}
+	if opts.Drop {
+		// --drop کالکشن‌های مقصد را قبل از بازیابی حذف می‌کند
+		args = append(args, "--drop")
+	}
+	if e.Oplog {
+		// آرشیو با --oplog گرفته شده؛ بازپخش آن داده‌ها را در یک لحظه سازگار می‌کند
+		args = append(args, "--oplogReplay")
+	}
+	args = append(args, remap.Args()...)
+	return engine.RunToolStream(ctx, mongoRestorePath, args, archive, nil, opts.Output)
+}
+
+func (e *Engine) ListContents(ctx context.Context, archive io.Reader) ([]string, error) {
+	r, err := NewArchiveReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	collections, err := ReadPrelude(r)
+	if err != nil {
+		return nil, err
+	}
+	return namespaces(collections), nil
+}
+
+// RestoredName implements engine.Renamer with Remap.
+func (e *Engine) RestoredName(ns string) (string, bool) {
+	if !strings.Contains(ns, ".") {
+		// oplog آرشیوهای --oplog بازپخش می‌شود و به عنوان کالکشن بازیابی نمی‌شود
+		return "", false
+	}
+	return e.Remap.Target(ns)
+}
+
+// namespaces returns the "db.collection" names of the archive entries.
+func namespaces(collections []ArchiveCollection) []string {
+	names := make([]string, 0, len(collections))
+	for _, c := range collections {
+		names = append(names, c.Namespace())
+	}
+	return names
+}
@@ -0,0 +1,171 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+)
+
+// OplogExtension is the extension of incremental oplog archives, e.g.
+// backup-2025-06-08_14-30-00.oplog.gz. Filtering ".gz" skips them.
+const OplogExtension = ".oplog.gz"
+
+// OplogFile is the file mongorestore --oplogReplay reads from the dump directory.
+const OplogFile = "oplog.bson"
+
+// Timestamp is a BSON timestamp, the position of an entry in the oplog. The
+// ordinal of real oplog entries starts at 1, so a zero I marks a position derived
+// from a wall clock time (see TimestampAt).
+type Timestamp struct {
+	T uint32 // seconds since the Unix epoch
+	I uint32 // ordinal within the second
+}
+
+// TimestampAt returns the oplog position at the start of the second of t.
+func TimestampAt(t time.Time) Timestamp {
+	return Timestamp{T: uint32(t.Unix())}
+}
+
+// ParseTimestamp parses "<seconds>[:<ordinal>]", the format of String and of
+// mongorestore --oplogLimit.
+func ParseTimestamp(s string) (Timestamp, error) {
+	secs, ord, _ := strings.Cut(s, ":")
+	t, err := strconv.ParseUint(secs, 10, 32)
+	if err != nil {
+		return Timestamp{}, fmt.Errorf("invalid oplog timestamp %q", s)
+	}
+	var i uint64
+	if ord != "" {
+		if i, err = strconv.ParseUint(ord, 10, 32); err != nil {
+			return Timestamp{}, fmt.Errorf("invalid oplog timestamp %q", s)
+		}
+	}
+	return Timestamp{T: uint32(t), I: uint32(i)}, nil
+}
+
+func (ts Timestamp) String() string { return fmt.Sprintf("%d:%d", ts.T, ts.I) }
+
+// IsZero reports whether ts is the zero timestamp.
+func (ts Timestamp) IsZero() bool { return ts == Timestamp{} }
+
+// Before reports whether ts comes before o in the oplog.
+func (ts Timestamp) Before(o Timestamp) bool {
+	return ts.T < o.T || ts.T == o.T && ts.I < o.I
+}
+
+// Time returns the wall clock time of ts.
+func (ts Timestamp) Time() time.Time { return time.Unix(int64(ts.T), 0) }
+
+// isOplogNamespace reports whether a namespace of an archive holds oplog entries:
+// "oplog" in dumps made with --oplog, "local.oplog.rs" in oplog slices.
+func isOplogNamespace(db, collection string) bool {
+	return db == "" && collection == "oplog" || db == "local" && collection == "oplog.rs"
+}
+
+// ReadOplog calls fn with every oplog entry of a decompressed mongodump archive
+// (see NewArchiveReader), in archive order. Other namespaces are skipped.
+func ReadOplog(r io.Reader, fn func(ts Timestamp, entry []byte) error) error {
+	return readArchive(r, func(db, collection string, doc bsonDoc) error {
+		if !isOplogNamespace(db, collection) {
+			return nil
+		}
+		ts, ok := doc.timestamp("ts")
+		if !ok {
+			return errors.New("reading archive: oplog entry without a timestamp")
+		}
+		return fn(ts, doc)
+	})
+}
+
+// OplogRange returns the first and last timestamps and the number of the oplog
+// entries in a gzip compressed mongodump archive stream.
+func OplogRange(archive io.Reader) (first, last Timestamp, n int, err error) {
+	r, err := NewArchiveReader(archive)
+	if err != nil {
+		return first, last, 0, err
+	}
+	defer r.Close()
+	err = ReadOplog(r, func(ts Timestamp, _ []byte) error {
+		if n == 0 {
+			first = ts
+		}
+		last = ts
+		n++
+		return nil
+	})
+	return first, last, n, err
+}
+
+// ReplayOplog applies the oplog entries in dir/OplogFile that come before limit to
+// the deployment behind uri, with mongorestore --oplogReplay --oplogLimit.
+func (e *Engine) ReplayOplog(ctx context.Context, uri, dir string, limit Timestamp, out io.Writer) error {
+	mongoRestorePath, err := engine.FindTool(e.ToolsPath, "mongorestore")
+	if err != nil {
+		return err
+	}
+	args := []string{
+		fmt.Sprintf("--uri=%s", uri),
+		"--oplogReplay",
+		fmt.Sprintf("--oplogLimit=%s", limit),
+		dir,
+	}
+	return engine.RunToolStream(ctx, mongoRestorePath, args, nil, nil, out)
+}
+
+// OplogSlice implements engine.Engine for incremental backups of the oplog of a
+// replica set. Its archives are not restored on their own but replayed on top of a
+// snapshot taken with Engine.Oplog.
+type OplogSlice struct {
+	// ToolsPath is the directory containing mongodump.
+	ToolsPath string
+	// Since is the position the slice continues from. Its entry is dumped again so
+	// that a gap caused by the oplog rolling over can be detected.
+	Since Timestamp
+}
+
+var _ engine.Engine = (*OplogSlice)(nil)
+
+func (s *OplogSlice) Name() string      { return "mongodb" }
+func (s *OplogSlice) Extension() string { return OplogExtension }
+
+func (s *OplogSlice) CheckTools() error {
+	_, err := engine.FindTool(s.ToolsPath, "mongodump")
+	return err
+}
+
+func (s *OplogSlice) Backup(ctx context.Context, opts engine.BackupOptions) (*engine.BackupResult, error) {
+	mongoDumpPath, err := engine.FindTool(s.ToolsPath, "mongodump")
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`{"ts":{"$gte":{"$timestamp":{"t":%d,"i":%d}}}}`, s.Since.T, s.Since.I)
+	args := []string{
+		fmt.Sprintf("--uri=%s", opts.URI),
+		"--archive",
+		"--gzip",
+		"--db=local",
+		"--collection=oplog.rs",
+		fmt.Sprintf("--query=%s", query),
+	}
+	if err := engine.RunToolStream(ctx, mongoDumpPath, args, nil, opts.Archive, opts.Output); err != nil {
+		return nil, err
+	}
+	return &engine.BackupResult{
+		ToolVersion: ToolVersion(mongoDumpPath),
+		Namespaces:  []string{"local.oplog.rs"},
+	}, nil
+}
+
+func (s *OplogSlice) Restore(ctx context.Context, opts engine.RestoreOptions) error {
+	return errors.New("oplog archives cannot be restored on their own; use 'restore mongo --until'")
+}
+
+func (s *OplogSlice) ListContents(ctx context.Context, archive io.Reader) ([]string, error) {
+	return (&Engine{}).ListContents(ctx, archive)
+}
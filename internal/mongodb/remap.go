@@ -0,0 +1,172 @@
+package mongodb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Remap selects the namespaces mongorestore restores and the names they are restored
+// under. Patterns are "db.collection" names with '*' wildcards, as in mongorestore.
+// An empty Remap restores everything under its original name.
+type Remap struct {
+	// From and To are pairs of --nsFrom/--nsTo patterns, e.g. "app.*" -> "app_copy.*".
+	From []string `json:"from,omitempty"`
+	To   []string `json:"to,omitempty"`
+	// TargetDB restores the collections of the archive's database into this one. It
+	// is turned into a From/To pair by Resolve once the archive is known.
+	TargetDB string `json:"target_db,omitempty"`
+	// Include and Exclude are --nsInclude/--nsExclude patterns, matched against the
+	// namespaces in the archive.
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// IsEmpty reports whether the remap changes what is restored in any way.
+func (r Remap) IsEmpty() bool {
+	return len(r.From) == 0 && r.TargetDB == "" && len(r.Include) == 0 && len(r.Exclude) == 0
+}
+
+// Validate checks the patterns before they are passed to mongorestore.
+func (r Remap) Validate() error {
+	if len(r.From) != len(r.To) {
+		return errors.New("every --ns-from needs a matching --ns-to")
+	}
+	if r.TargetDB != "" && len(r.From) > 0 {
+		return errors.New("--target-db cannot be combined with --ns-from/--ns-to")
+	}
+	if strings.ContainsAny(r.TargetDB, "/\\. \"$*") {
+		return fmt.Errorf("invalid database name %q", r.TargetDB)
+	}
+	patterns := append(append(append([]string{}, r.From...), r.To...), append(r.Include, r.Exclude...)...)
+	for _, p := range patterns {
+		if !strings.Contains(p, ".") {
+			return fmt.Errorf("invalid namespace pattern %q (expected <db>.<collection>, e.g. app.*)", p)
+		}
+	}
+	for i := range r.From {
+		// mongorestore کاراکترهای '*' مبدأ را به ترتیب در مقصد جایگذاری می‌کند
+		if strings.Count(r.From[i], "*") != strings.Count(r.To[i], "*") {
+			return fmt.Errorf("%q and %q must have the same number of '*' wildcards", r.From[i], r.To[i])
+		}
+	}
+	return nil
+}
+
+// Args returns the mongorestore arguments of the remap. TargetDB must have been
+// resolved (see Resolve).
+func (r Remap) Args() []string {
+	var args []string
+	for _, p := range r.Include {
+		args = append(args, fmt.Sprintf("--nsInclude=%s", p))
+	}
+	for _, p := range r.Exclude {
+		args = append(args, fmt.Sprintf("--nsExclude=%s", p))
+	}
+	for i := range r.From {
+		args = append(args, fmt.Sprintf("--nsFrom=%s", r.From[i]), fmt.Sprintf("--nsTo=%s", r.To[i]))
+	}
+	return args
+}
+
+// Resolve turns TargetDB into a From/To pair for the database of the given archive
+// namespaces. It fails if the restored namespaces span several databases.
+func (r Remap) Resolve(namespaces []string) (Remap, error) {
+	if r.TargetDB == "" {
+		return r, nil
+	}
+	seen := map[string]bool{}
+	var databases []string
+	for _, ns := range namespaces {
+		db, _, ok := strings.Cut(ns, ".")
+		if !ok || !r.included(ns) || seen[db] {
+			continue
+		}
+		seen[db] = true
+		databases = append(databases, db)
+	}
+	sort.Strings(databases)
+	switch len(databases) {
+	case 0:
+		return r, fmt.Errorf("the archive has no collections to restore into %q", r.TargetDB)
+	case 1:
+	default:
+		return r, fmt.Errorf("--target-db needs a single source database but the archive contains %s; pick one with --ns-include (e.g. %s.*) or use --ns-from/--ns-to", strings.Join(databases, ", "), databases[0])
+	}
+	r.From = []string{databases[0] + ".*"}
+	r.To = []string{r.TargetDB + ".*"}
+	r.TargetDB = ""
+	return r, nil
+}
+
+// Target returns the name the archive namespace ns is restored as, and false if it
+// is not restored at all.
+func (r Remap) Target(ns string) (string, bool) {
+	if !r.included(ns) {
+		return "", false
+	}
+	for i, from := range r.From {
+		if parts := matchNamespace(from, ns); parts != nil {
+			to := r.To[i]
+			for _, part := range parts {
+				to = strings.Replace(to, "*", part, 1)
+			}
+			return to, true
+		}
+	}
+	if r.TargetDB != "" {
+		if _, collection, ok := strings.Cut(ns, "."); ok {
+			return r.TargetDB + "." + collection, true
+		}
+	}
+	return ns, true
+}
+
+// included applies Include and Exclude to an archive namespace.
+func (r Remap) included(ns string) bool {
+	matchesAny := func(patterns []string) bool {
+		for _, p := range patterns {
+			if matchNamespace(p, ns) != nil {
+				return true
+			}
+		}
+		return false
+	}
+	if len(r.Include) > 0 && !matchesAny(r.Include) {
+		return false
+	}
+	return !matchesAny(r.Exclude)
+}
+
+// matchNamespace matches ns against a pattern with '*' wildcards and returns the
+// text matched by each wildcard, or nil if it does not match.
+func matchNamespace(pattern, ns string) []string {
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	m := regexp.MustCompile("^" + strings.Join(parts, "(.*)") + "$").FindStringSubmatch(ns)
+	if m == nil {
+		return nil
+	}
+	return m[1:]
+}
+
+// peekPrelude reads the prelude of a gzip compressed archive stream and returns its
+// namespaces together with a reader that yields the complete stream again.
+func peekPrelude(archive io.Reader) ([]ArchiveCollection, io.Reader, error) {
+	var consumed bytes.Buffer
+	r, err := NewArchiveReader(io.TeeReader(archive, &consumed))
+	if err != nil {
+		return nil, nil, err
+	}
+	collections, err := ReadPrelude(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return collections, io.MultiReader(&consumed, archive), nil
+}
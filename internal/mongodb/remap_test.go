@@ -0,0 +1,116 @@
+package mongodb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRemapValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		remap Remap
+		want  string // "" if valid
+	}{
+		{"empty", Remap{}, ""},
+		{"wildcard pair", Remap{From: []string{"app.*"}, To: []string{"copy.*"}}, ""},
+		{"two wildcards", Remap{From: []string{"*.users_*"}, To: []string{"*_copy.u_*"}}, ""},
+		{"target db with filters", Remap{TargetDB: "qa", Include: []string{"app.*"}, Exclude: []string{"app.sessions"}}, ""},
+		{"unpaired from", Remap{From: []string{"app.*", "shop.*"}, To: []string{"copy.*"}}, "matching --ns-to"},
+		{"target db and pairs", Remap{TargetDB: "qa", From: []string{"app.*"}, To: []string{"qa.*"}}, "cannot be combined"},
+		{"target db with a dot", Remap{TargetDB: "qa.users"}, "invalid database name"},
+		{"target db with a wildcard", Remap{TargetDB: "qa*"}, "invalid database name"},
+		{"pattern without a collection", Remap{From: []string{"app"}, To: []string{"copy.*"}}, "invalid namespace pattern"},
+		{"include without a collection", Remap{Include: []string{"app"}}, "invalid namespace pattern"},
+		{"wildcard count differs", Remap{From: []string{"*.*"}, To: []string{"copy.*"}}, "same number of '*' wildcards"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.remap.Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Fatalf("Validate() = %v, want no error", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Fatalf("Validate() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemapTarget(t *testing.T) {
+	tests := []struct {
+		name  string
+		remap Remap
+		ns    string
+		want  string // "" if not restored
+	}{
+		{"unchanged", Remap{}, "app.users", "app.users"},
+		{"database wildcard", Remap{From: []string{"app.*"}, To: []string{"copy.*"}}, "app.users", "copy.users"},
+		{"other database untouched", Remap{From: []string{"app.*"}, To: []string{"copy.*"}}, "shop.orders", "shop.orders"},
+		{"wildcards in order", Remap{From: []string{"*.users_*"}, To: []string{"*_copy.u_*"}}, "app.users_2024", "app_copy.u_2024"},
+		{"exact pair", Remap{From: []string{"app.users"}, To: []string{"app.people"}}, "app.users", "app.people"},
+		{"dots are literal", Remap{From: []string{"app.u.ers"}, To: []string{"x.y"}}, "app.users", "app.users"},
+		{"first matching pair wins", Remap{From: []string{"app.users", "app.*"}, To: []string{"a.people", "b.*"}}, "app.users", "a.people"},
+		{"target db", Remap{TargetDB: "qa"}, "app.users", "qa.users"},
+		{"excluded", Remap{Exclude: []string{"app.sessions"}}, "app.sessions", ""},
+		{"not included", Remap{Include: []string{"shop.*"}}, "app.users", ""},
+		{"included and renamed", Remap{Include: []string{"app.*"}, From: []string{"app.*"}, To: []string{"copy.*"}}, "app.users", "copy.users"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.remap.Target(tt.ns)
+			if !ok {
+				got = ""
+			}
+			if got != tt.want {
+				t.Fatalf("Target(%q) = %q, want %q", tt.ns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemapResolve(t *testing.T) {
+	oneDB := []string{"app.users", "app.orders"}
+	twoDBs := []string{"app.users", "shop.orders", "shop.items"}
+	tests := []struct {
+		name       string
+		remap      Remap
+		namespaces []string
+		wantFrom   []string
+		wantTo     []string
+		wantErr    string
+	}{
+		{"no target db", Remap{From: []string{"app.*"}, To: []string{"copy.*"}}, twoDBs, []string{"app.*"}, []string{"copy.*"}, ""},
+		{"one database", Remap{TargetDB: "qa"}, oneDB, []string{"app.*"}, []string{"qa.*"}, ""},
+		{"several databases", Remap{TargetDB: "qa"}, twoDBs, nil, nil, "contains app, shop"},
+		{"several databases narrowed by include", Remap{TargetDB: "qa", Include: []string{"shop.*"}}, twoDBs, []string{"shop.*"}, []string{"qa.*"}, ""},
+		{"several databases narrowed by exclude", Remap{TargetDB: "qa", Exclude: []string{"app.*"}}, twoDBs, []string{"shop.*"}, []string{"qa.*"}, ""},
+		{"nothing left to restore", Remap{TargetDB: "qa", Include: []string{"other.*"}}, twoDBs, nil, nil, "no collections"},
+		{"oplog entry ignored", Remap{TargetDB: "qa"}, append([]string{"oplog"}, oneDB...), []string{"app.*"}, []string{"qa.*"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.remap.Resolve(tt.namespaces)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Resolve() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(): %v", err)
+			}
+			if got.TargetDB != "" || !reflect.DeepEqual(got.From, tt.wantFrom) || !reflect.DeepEqual(got.To, tt.wantTo) {
+				t.Fatalf("Resolve() = %+v, want From %v To %v", got, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestRemapArgs(t *testing.T) {
+	r := Remap{From: []string{"app.*"}, To: []string{"copy.*"}, Include: []string{"app.*"}, Exclude: []string{"app.sessions"}}
+	want := []string{"--nsInclude=app.*", "--nsExclude=app.sessions", "--nsFrom=app.*", "--nsTo=copy.*"}
+	if got := r.Args(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Args() = %v, want %v", got, want)
+	}
+}
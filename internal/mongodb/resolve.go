@@ -0,0 +1,76 @@
+package mongodb
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+)
+
+// RequiredTools are the executables the MongoDB engine needs in one directory.
+var RequiredTools = []string{"mongodump", "mongorestore"}
+
+// ResolveTools finds the directory containing mongodump and mongorestore. It checks
+// the configured directory, PATH, the well-known install locations of the OS package
+// managers, and finally localDirs (e.g. the versions installed under ./tools, newest
+// first). The returned chain lists every location that was checked.
+func ResolveTools(configured string, localDirs []string) (string, []engine.ToolCandidate, error) {
+	var candidates []engine.ToolCandidate
+	if configured != "" {
+		candidates = append(candidates, engine.ToolCandidate{Source: "paths.mongo_tools", Dir: configured})
+	} else {
+		candidates = append(candidates, engine.ToolCandidate{Source: "paths.mongo_tools", Err: errors.New("not configured")})
+	}
+	candidates = append(candidates, engine.ToolCandidate{Source: "PATH", Dir: engine.LookPathDir("mongodump")})
+	for _, dir := range wellKnownToolDirs() {
+		candidates = append(candidates, engine.ToolCandidate{Source: "well-known location", Dir: dir})
+	}
+	for _, dir := range localDirs {
+		candidates = append(candidates, engine.ToolCandidate{Source: "local tools", Dir: dir})
+	}
+	return engine.ResolveToolDir(RequiredTools, dedupCandidates(candidates))
+}
+
+// wellKnownToolDirs returns the directories the MongoDB Database Tools are installed to
+// by the official packages, Homebrew, MacPorts and the Windows MSI.
+func wellKnownToolDirs() []string {
+	switch runtime.GOOS {
+	case "windows":
+		var dirs []string
+		for _, env := range []string{"ProgramFiles", "ProgramW6432"} {
+			if root := os.Getenv(env); root != "" {
+				// مسیر نصب MSI به شکل "C:\Program Files\MongoDB\Tools\100\bin" است
+				matches, _ := filepath.Glob(filepath.Join(root, "MongoDB", "Tools", "*", "bin"))
+				dirs = append(dirs, matches...)
+			}
+		}
+		return dirs
+	case "darwin":
+		return []string{"/opt/homebrew/bin", "/usr/local/bin", "/opt/local/bin"}
+	default:
+		return []string{"/usr/bin", "/usr/local/bin", "/opt/mongodb-database-tools/bin", "/snap/bin"}
+	}
+}
+
+// dedupCandidates drops candidates whose directory was already listed, e.g. a
+// well-known directory that is also on PATH.
+func dedupCandidates(candidates []engine.ToolCandidate) []engine.ToolCandidate {
+	seen := make(map[string]bool)
+	var out []engine.ToolCandidate
+	for _, c := range candidates {
+		if c.Dir != "" {
+			key, err := filepath.Abs(c.Dir)
+			if err != nil {
+				key = c.Dir
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package mongodb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Selection describes which databases and collections a mongodump run should include.
+// An empty Selection means "everything reachable through the connection URI".
+type Selection struct {
+	Database                  string   `json:"db,omitempty"`
+	Collection                string   `json:"collection,omitempty"`
+	ExcludeCollections        []string `json:"exclude_collections,omitempty"`
+	ExcludeCollectionPrefixes []string `json:"exclude_collection_prefixes,omitempty"`
+}
+
+// IsEmpty reports whether the selection narrows the dump in any way.
+func (s Selection) IsEmpty() bool {
+	return s.Database == "" && s.Collection == "" &&
+		len(s.ExcludeCollections) == 0 && len(s.ExcludeCollectionPrefixes) == 0
+}
+
+// Validate checks the combinations that mongodump itself would reject.
+func (s Selection) Validate() error {
+	hasExcludes := len(s.ExcludeCollections) > 0 || len(s.ExcludeCollectionPrefixes) > 0
+	if s.Database == "" && (s.Collection != "" || hasExcludes) {
+		return errors.New("--collection and the exclude options require --db to be set")
+	}
+	if s.Collection != "" && hasExcludes {
+		return errors.New("--collection cannot be combined with --exclude-collection or --exclude-collections-with-prefix")
+	}
+	return nil
+}
+
+// Args returns the mongodump arguments for this selection.
+func (s Selection) Args() []string {
+	var args []string
+	if s.Database != "" {
+		args = append(args, fmt.Sprintf("--db=%s", s.Database))
+	}
+	if s.Collection != "" {
+		args = append(args, fmt.Sprintf("--collection=%s", s.Collection))
+	}
+	for _, c := range s.ExcludeCollections {
+		args = append(args, fmt.Sprintf("--excludeCollection=%s", c))
+	}
+	for _, p := range s.ExcludeCollectionPrefixes {
+		args = append(args, fmt.Sprintf("--excludeCollectionsWithPrefix=%s", p))
+	}
+	return args
+}
+
+// Namespaces returns the included namespaces as "db.collection" patterns.
+func (s Selection) Namespaces() []string {
+	switch {
+	case s.Database == "":
+		return []string{"*.*"}
+	case s.Collection == "":
+		return []string{s.Database + ".*"}
+	default:
+		return []string{s.Database + "." + s.Collection}
+	}
+}
+
+// ExcludedNamespaces returns the excluded namespaces as "db.collection" patterns.
+// Prefix exclusions are reported with a trailing '*'.
+func (s Selection) ExcludedNamespaces() []string {
+	var excluded []string
+	for _, c := range s.ExcludeCollections {
+		excluded = append(excluded, s.Database+"."+c)
+	}
+	for _, p := range s.ExcludeCollectionPrefixes {
+		excluded = append(excluded, s.Database+"."+p+"*")
+	}
+	return excluded
+}
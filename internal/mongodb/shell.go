@@ -0,0 +1,187 @@
+package mongodb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+)
+
+// shellTool is the MongoDB shell. It is not part of the Database Tools, so it is
+// looked up in ToolsPath first and then on PATH.
+const shellTool = "mongosh"
+
+// ShellPath returns the path of mongosh, which the commands that inspect or change a
+// deployment after a restore need.
+func (e *Engine) ShellPath() (string, error) {
+	if e.ToolsPath != "" {
+		if path, err := engine.FindTool(e.ToolsPath, shellTool); err == nil {
+			return path, nil
+		}
+	}
+	path, err := engine.FindTool("", shellTool)
+	if err != nil {
+		return "", fmt.Errorf("%s not found in the tools directory or on PATH; it is installed separately from the Database Tools", shellTool)
+	}
+	return path, nil
+}
+
+// eval runs script with mongosh against the deployment behind uri and returns the
+// lines it printed. Scripts receive their arguments as JSON literals, never as text
+// spliced into the code.
+func (e *Engine) eval(ctx context.Context, uri, script string) ([]string, error) {
+	shellPath, err := e.ShellPath()
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	c := engine.Command(ctx, shellPath, uri, "--quiet", "--norc", "--eval", script)
+	c.Stdout, c.Stderr = &stdout, &stderr
+	err = c.Run()
+	lines := outputLines(&stdout)
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" && len(lines) > 0 {
+			msg = lines[len(lines)-1]
+		}
+		if msg == "" {
+			return lines, fmt.Errorf("%s failed: %w", shellTool, err)
+		}
+		return lines, fmt.Errorf("%s failed: %s", shellTool, errorLine(msg))
+	}
+	return lines, nil
+}
+
+// CollectionStats counts the documents and lists the indexes of the given
+// namespaces in the deployment behind uri. The counts come from the collection
+// metadata (estimatedDocumentCount), so no collection is scanned. Missing collections
+// are reported with Exists unset.
+func (e *Engine) CollectionStats(ctx context.Context, uri string, namespaces []string) ([]CollectionStats, error) {
+	arg, err := json.Marshal(namespaces)
+	if err != nil {
+		return nil, err
+	}
+	script := `
+for (const ns of ` + string(arg) + `) {
+  const i = ns.indexOf(".");
+  const d = db.getSiblingDB(ns.slice(0, i)), name = ns.slice(i + 1);
+  const exists = d.getCollectionNames().includes(name);
+  const c = d.getCollection(name);
+  print(EJSON.stringify({
+    namespace: ns,
+    exists: exists,
+    documents: exists ? c.estimatedDocumentCount() : 0,
+    indexes: exists ? c.getIndexes().map(idx => ({name: idx.name, key: idx.key})) : [],
+  }, {relaxed: true}));
+}`
+	lines, err := e.eval(ctx, uri, script)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]CollectionStats, 0, len(namespaces))
+	for _, line := range lines {
+		var s struct {
+			Namespace string     `json:"namespace"`
+			Exists    bool       `json:"exists"`
+			Documents int64      `json:"documents"`
+			Indexes   []rawIndex `json:"indexes"`
+		}
+		if err := json.Unmarshal([]byte(line), &s); err != nil {
+			return nil, fmt.Errorf("unexpected %s output %q: %w", shellTool, line, err)
+		}
+		indexes, err := indexDefinitions(s.Indexes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Namespace, err)
+		}
+		stats = append(stats, CollectionStats{Namespace: s.Namespace, Exists: s.Exists, Documents: s.Documents, Indexes: indexes})
+	}
+	if len(stats) != len(namespaces) {
+		return nil, fmt.Errorf("%s reported %d of %d collections", shellTool, len(stats), len(namespaces))
+	}
+	return stats, nil
+}
+
+// RenameCollections renames every from[i] to to[i] in the deployment behind uri,
+// replacing existing collections (renameCollection with dropTarget). Both names must
+// be in the same database: such a rename only changes metadata, and replaces the
+// target in a single step. The renames are done one after another, not as a whole.
+// It returns how many renames were done, also on failure.
+func (e *Engine) RenameCollections(ctx context.Context, uri string, from, to []string) (int, error) {
+	pairs := make([][2]string, len(from))
+	for i := range from {
+		fromDB, _, _ := strings.Cut(from[i], ".")
+		toDB, _, _ := strings.Cut(to[i], ".")
+		if fromDB != toDB {
+			return 0, fmt.Errorf("cannot rename %s to %s: renames across databases copy the data", from[i], to[i])
+		}
+		pairs[i] = [2]string{from[i], to[i]}
+	}
+	arg, err := json.Marshal(pairs)
+	if err != nil {
+		return 0, err
+	}
+	script := `
+for (const [from, to] of ` + string(arg) + `) {
+  const res = db.adminCommand({renameCollection: from, to: to, dropTarget: true});
+  if (!res.ok) throw new Error("renaming " + from + " to " + to + ": " + res.errmsg);
+  print(to);
+}`
+	lines, err := e.eval(ctx, uri, script)
+	done := 0
+	for _, line := range lines {
+		if done < len(to) && line == to[done] {
+			done++
+		}
+	}
+	return done, err
+}
+
+// DropCollections drops the given namespaces in the deployment behind uri. Missing
+// collections are ignored.
+func (e *Engine) DropCollections(ctx context.Context, uri string, namespaces []string) error {
+	arg, err := json.Marshal(namespaces)
+	if err != nil {
+		return err
+	}
+	script := `
+for (const ns of ` + string(arg) + `) {
+  const i = ns.indexOf(".");
+  db.getSiblingDB(ns.slice(0, i)).getCollection(ns.slice(i + 1)).drop();
+}`
+	_, err = e.eval(ctx, uri, script)
+	return err
+}
+
+// outputLines returns the non-empty lines of r.
+func outputLines(r io.Reader) []string {
+	var lines []string
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// errorLinePattern matches lines such as "MongoServerError: ..." or "Uncaught Error: ...".
+var errorLinePattern = regexp.MustCompile(`^(Uncaught:? )?\w*Error: `)
+
+// errorLine picks the line naming the error from the output of a failed script,
+// e.g. "MongoServerError: ns not found", or the last line if there is none.
+func errorLine(msg string) string {
+	lines := strings.Split(msg, "\n")
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); errorLinePattern.MatchString(line) {
+			return line
+		}
+	}
+	return strings.TrimSpace(lines[len(lines)-1])
+}
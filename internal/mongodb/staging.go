@@ -0,0 +1,45 @@
+package mongodb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// stagingPrefix is prepended to the collection names of a staged restore.
+const stagingPrefix = "__dwstaging."
+
+// maxNamespace is the longest namespace ("<db>.<collection>", in bytes) MongoDB accepts.
+const maxNamespace = 255
+
+// Staging describes a staged restore: the archive is first restored into staging
+// collections inside the target databases ("<db>.__dwstaging.<collection>") and the
+// collections are renamed over the targets once the staged copy has been verified.
+// Each swap is a rename within one database, which only changes metadata.
+type Staging struct {
+	// Remap restores the archive namespaces into the staging namespaces only.
+	Remap Remap
+	// Namespaces are the staging namespaces, in the order of the targets.
+	Namespaces []string
+}
+
+// NewStaging plans the staged restore of the archive namespaces sources, which are
+// restored as targets.
+func NewStaging(sources, targets []string) (Staging, error) {
+	var s Staging
+	for i, target := range targets {
+		db, collection, ok := strings.Cut(target, ".")
+		if !ok {
+			return s, fmt.Errorf("invalid namespace %q", target)
+		}
+		staging := db + "." + stagingPrefix + collection
+		if len(staging) > maxNamespace {
+			return s, fmt.Errorf("the staging namespace %q is longer than %d bytes", staging, maxNamespace)
+		}
+		s.Namespaces = append(s.Namespaces, staging)
+		// الگوهای بدون '*' فقط با همان namespace تطبیق داده می‌شوند
+		s.Remap.From = append(s.Remap.From, sources[i])
+		s.Remap.To = append(s.Remap.To, staging)
+	}
+	s.Remap.Include = append([]string(nil), sources...)
+	return s, nil
+}
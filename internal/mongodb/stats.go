@@ -0,0 +1,243 @@
+package mongodb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CollectionStats describes the contents of a collection: its number of documents
+// and its indexes.
+type CollectionStats struct {
+	Namespace string  `json:"namespace"`
+	Exists    bool    `json:"exists"`
+	Documents int64   `json:"documents"`
+	Indexes   []Index `json:"indexes"`
+}
+
+// Index is an index definition: its name and key pattern, e.g. "email_1" and
+// "email: 1".
+type Index struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// ArchiveStats returns the statistics of every collection in a gzip compressed
+// mongodump archive stream: the documents are counted and the indexes are read from
+// the metadata mongodump recorded. The oplog of --oplog archives is skipped.
+func ArchiveStats(archive io.Reader) ([]CollectionStats, error) {
+	r, err := NewArchiveReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	collections, rest, err := preludeReader(r)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]CollectionStats, 0, len(collections))
+	byNamespace := map[string]*CollectionStats{}
+	for _, c := range collections {
+		if isOplogNamespace(c.DB, c.Collection) {
+			continue
+		}
+		indexes, err := metadataIndexes(c.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("reading the metadata of %s: %w", c.Namespace(), err)
+		}
+		stats = append(stats, CollectionStats{Namespace: c.Namespace(), Exists: true, Indexes: indexes})
+	}
+	for i := range stats {
+		byNamespace[stats[i].Namespace] = &stats[i]
+	}
+
+	err = readArchive(rest, func(db, collection string, _ bsonDoc) error {
+		if s := byNamespace[ArchiveCollection{DB: db, Collection: collection}.Namespace()]; s != nil {
+			s.Documents++
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// ArchiveCounter collects the statistics of an archive (see ArchiveStats) from the
+// bytes another consumer, such as mongorestore, reads through it, so the archive is
+// not read a second time.
+type ArchiveCounter struct {
+	r     io.Reader
+	pw    *io.PipeWriter
+	done  chan struct{}
+	stats []CollectionStats
+	err   error
+}
+
+// CountArchive returns an ArchiveCounter reading the gzip compressed mongodump
+// archive stream archive. Stats or Close must be called once the consumer is done.
+func CountArchive(archive io.Reader) *ArchiveCounter {
+	pr, pw := io.Pipe()
+	c := &ArchiveCounter{r: io.TeeReader(archive, pw), pw: pw, done: make(chan struct{})}
+	go func() {
+		defer close(c.done)
+		c.stats, c.err = ArchiveStats(pr)
+		// اگر شمارش زودتر متوقف شود، بقیه خوانده و دور ریخته می‌شود تا مصرف‌کننده منتظر نماند
+		io.Copy(io.Discard, pr)
+	}()
+	return c
+}
+
+func (c *ArchiveCounter) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// Stats reads what the consumer left of the archive (such as the gzip trailer) and
+// returns the statistics of its collections.
+func (c *ArchiveCounter) Stats() ([]CollectionStats, error) {
+	_, err := io.Copy(io.Discard, c.r)
+	c.pw.CloseWithError(err)
+	<-c.done
+	if err != nil {
+		return nil, err
+	}
+	return c.stats, c.err
+}
+
+// Close stops counting without reading the rest of the archive.
+func (c *ArchiveCounter) Close() error {
+	c.pw.CloseWithError(errors.New("archive counting stopped"))
+	<-c.done
+	return nil
+}
+
+// Compare checks actual against the expected statistics s: the collection must
+// exist with the same number of documents and every index of s, with the same key
+// pattern. If merged is set, the archive was restored on top of existing data and
+// more documents than expected are accepted. It returns the differences found, or
+// nothing if they match. Extra indexes in actual are not reported.
+func (s CollectionStats) Compare(actual CollectionStats, merged bool) []string {
+	if !actual.Exists {
+		return []string{"collection is missing"}
+	}
+	var problems []string
+	if actual.Documents < s.Documents || actual.Documents > s.Documents && !merged {
+		problems = append(problems, fmt.Sprintf("%d documents, expected %d", actual.Documents, s.Documents))
+	}
+	keys := make(map[string]string, len(actual.Indexes))
+	for _, idx := range actual.Indexes {
+		keys[idx.Name] = idx.Key
+	}
+	for _, idx := range s.Indexes {
+		key, ok := keys[idx.Name]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("index %s is missing", idx.Name))
+		case key != idx.Key:
+			problems = append(problems, fmt.Sprintf("index %s has key {%s}, expected {%s}", idx.Name, key, idx.Key))
+		}
+	}
+	return problems
+}
+
+// preludeReader reads the prelude of a decompressed archive and returns its
+// namespaces together with a reader that yields the complete archive again, as
+// readArchive expects it.
+func preludeReader(r io.Reader) ([]ArchiveCollection, io.Reader, error) {
+	var consumed bytes.Buffer
+	collections, err := ReadPrelude(io.TeeReader(r, &consumed))
+	if err != nil {
+		return nil, nil, err
+	}
+	return collections, io.MultiReader(&consumed, r), nil
+}
+
+// metadataIndexes returns the indexes listed in the metadata of an archive entry.
+func metadataIndexes(metadata string) ([]Index, error) {
+	if metadata == "" {
+		return nil, nil
+	}
+	var m struct {
+		Indexes []rawIndex `json:"indexes"`
+	}
+	if err := json.Unmarshal([]byte(metadata), &m); err != nil {
+		return nil, err
+	}
+	return indexDefinitions(m.Indexes)
+}
+
+// rawIndex is an index as printed in extended JSON by mongodump and mongosh.
+type rawIndex struct {
+	Name string          `json:"name"`
+	Key  json.RawMessage `json:"key"`
+}
+
+// indexDefinitions normalizes the key patterns of raw indexes, sorted by name.
+func indexDefinitions(raw []rawIndex) ([]Index, error) {
+	indexes := make([]Index, 0, len(raw))
+	for _, idx := range raw {
+		key, err := indexKey(idx.Key)
+		if err != nil {
+			return nil, fmt.Errorf("index %s: %w", idx.Name, err)
+		}
+		indexes = append(indexes, Index{Name: idx.Name, Key: key})
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].Name < indexes[j].Name })
+	return indexes, nil
+}
+
+// indexKey formats the key pattern of an index as "field: value, ...", keeping the
+// order of the fields. Numbers in canonical ({"$numberInt": "1"}) and relaxed
+// extended JSON are formatted the same way.
+func indexKey(raw json.RawMessage) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return "", fmt.Errorf("invalid key pattern %s", raw)
+	}
+	var fields []string
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return "", err
+		}
+		fields = append(fields, fmt.Sprintf("%s: %s", t, keyValue(value)))
+	}
+	return strings.Join(fields, ", "), nil
+}
+
+// keyValue formats the value of a key pattern field: 1, -1 or an index type such
+// as "text" or "2dsphere".
+func keyValue(v any) string {
+	switch v := v.(type) {
+	case json.Number:
+		return formatNumber(string(v))
+	case map[string]any:
+		// اعداد extended JSON کانونیکال مثل {"$numberInt": "1"}
+		for _, k := range []string{"$numberInt", "$numberLong", "$numberDouble", "$numberDecimal"} {
+			if s, ok := v[k].(string); ok {
+				return formatNumber(s)
+			}
+		}
+	case string:
+		return strconv.Quote(v)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// formatNumber prints 1, 1.0 and 1e0 alike.
+func formatNumber(s string) string {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return s
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,21 @@
+package mongodb
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// ToolVersion runs '<tool> --version' and returns the reported version, e.g. "100.12.2".
+// An empty string is returned if the version cannot be determined.
+func ToolVersion(toolPath string) string {
+	out, err := exec.Command(toolPath, "--version").Output()
+	if err != nil {
+		return ""
+	}
+	// خط اول خروجی به شکل "mongodump version: 100.12.2" است
+	firstLine := strings.SplitN(strings.TrimSpace(string(out)), "\n", 2)[0]
+	if i := strings.LastIndex(firstLine, ":"); i >= 0 {
+		return strings.TrimSpace(firstLine[i+1:])
+	}
+	return strings.TrimSpace(firstLine)
+}
@@ -0,0 +1,138 @@
+// Package postgres implements the PostgreSQL engine on top of pg_dump and pg_restore.
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/mshamsi502/dataweaver-cli/internal/engine"
+)
+
+// Engine implements engine.Engine with pg_dump and pg_restore, using custom-format archives.
+type Engine struct {
+	// ToolsPath is the directory containing pg_dump and pg_restore. If empty, PATH is searched.
+	ToolsPath string
+	// Schemas, Tables and ExcludeTables narrow down what Backup dumps (pg_dump patterns).
+	Schemas       []string
+	Tables        []string
+	ExcludeTables []string
+}
+
+var _ engine.Engine = (*Engine)(nil)
+
+func (e *Engine) Name() string      { return "postgres" }
+func (e *Engine) Extension() string { return ".dump" }
+
+func (e *Engine) CheckTools() error {
+	for _, tool := range []string{"pg_dump", "pg_restore"} {
+		if _, err := engine.FindTool(e.ToolsPath, tool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Engine) Backup(ctx context.Context, opts engine.BackupOptions) (*engine.BackupResult, error) {
+	pgDumpPath, err := engine.FindTool(e.ToolsPath, "pg_dump")
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
+		"--format=custom",
+		"--verbose",
+		fmt.Sprintf("--dbname=%s", opts.URI),
+	}
+	for _, s := range e.Schemas {
+		args = append(args, fmt.Sprintf("--schema=%s", s))
+	}
+	for _, t := range e.Tables {
+		args = append(args, fmt.Sprintf("--table=%s", t))
+	}
+	for _, t := range e.ExcludeTables {
+		args = append(args, fmt.Sprintf("--exclude-table=%s", t))
+	}
+	// بدون --file، pg_dump آرشیو را روی stdout می‌نویسد
+	if err := engine.RunToolStream(ctx, pgDumpPath, args, nil, opts.Archive, opts.Output); err != nil {
+		return nil, err
+	}
+
+	// Namespaces خالی می‌ماند تا فهرست جداول از خود آرشیو خوانده شود
+	return &engine.BackupResult{
+		ToolVersion:        toolVersion(pgDumpPath),
+		ExcludedNamespaces: e.ExcludeTables,
+	}, nil
+}
+
+func (e *Engine) Restore(ctx context.Context, opts engine.RestoreOptions) error {
+	pgRestorePath, err := engine.FindTool(e.ToolsPath, "pg_restore")
+	if err != nil {
+		return err
+	}
+	args := []string{
+		"--verbose",
+		"--no-owner",
+		fmt.Sprintf("--dbname=%s", opts.URI),
+	}
+	if opts.Drop {
+		args = append(args, "--clean", "--if-exists")
+	}
+	// بدون نام فایل، pg_restore آرشیو را از stdin می‌خواند
+	return engine.RunToolStream(ctx, pgRestorePath, args, opts.Archive, nil, opts.Output)
+}
+
+// ListContents returns the tables in the archive as "schema.table".
+func (e *Engine) ListContents(ctx context.Context, archive io.Reader) ([]string, error) {
+	pgRestorePath, err := engine.FindTool(e.ToolsPath, "pg_restore")
+	if err != nil {
+		return nil, err
+	}
+	c := engine.Command(ctx, pgRestorePath, "--list")
+	c.Stdin = archive
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("pg_restore --list failed: %w", err)
+	}
+	return parseTableOfContents(out), nil
+}
+
+// parseTableOfContents extracts the TABLE entries of 'pg_restore --list' output, whose
+// lines look like "215; 1259 16386 TABLE public users postgres".
+func parseTableOfContents(toc []byte) []string {
+	var tables []string
+	scanner := bufio.NewScanner(bytes.NewReader(toc))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+		_, entry, ok := strings.Cut(line, ";")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(entry)
+		// fields: tableoid oid desc schema name owner
+		if len(fields) >= 6 && fields[2] == "TABLE" && fields[3] != "DATA" {
+			tables = append(tables, fields[3]+"."+fields[4])
+		}
+	}
+	return tables
+}
+
+// toolVersion returns the version reported by e.g. "pg_dump (PostgreSQL) 16.2".
+func toolVersion(toolPath string) string {
+	out, err := exec.Command(toolPath, "--version").Output()
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
@@ -0,0 +1,134 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestNext(t *testing.T) {
+	// 2026-03-04 is a Wednesday.
+	from := time.Date(2026, 3, 4, 10, 7, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want []string
+	}{
+		{"every minute", "* * * * *", from, []string{"2026-03-04 10:08", "2026-03-04 10:09"}},
+		{"step", "*/15 * * * *", from, []string{"2026-03-04 10:15", "2026-03-04 10:30", "2026-03-04 10:45", "2026-03-04 11:00"}},
+		{"step from a value", "5/20 * * * *", from, []string{"2026-03-04 10:25", "2026-03-04 10:45", "2026-03-04 11:05"}},
+		{"step over a range", "0 8-16/4 * * *", from, []string{"2026-03-04 12:00", "2026-03-04 16:00", "2026-03-05 08:00"}},
+		{"range", "0 9-11 * * *", from, []string{"2026-03-04 11:00", "2026-03-05 09:00"}},
+		{"list", "0,30 6,18 * * *", from, []string{"2026-03-04 18:00", "2026-03-04 18:30", "2026-03-05 06:00"}},
+		{"month names", "0 0 1 jan,JUL *", from, []string{"2026-07-01 00:00", "2027-01-01 00:00"}},
+		{"day names", "30 2 * * mon-fri", from, []string{"2026-03-05 02:30", "2026-03-06 02:30", "2026-03-09 02:30"}},
+		{"sunday as 7", "0 0 * * 7", from, []string{"2026-03-08 00:00", "2026-03-15 00:00"}},
+		{"day of month only", "0 0 10 * *", from, []string{"2026-03-10 00:00", "2026-04-10 00:00"}},
+		// Both day fields are restricted: the 13th of the month or any Friday.
+		{"day of month or day of week", "0 0 13 * fri", from, []string{"2026-03-06 00:00", "2026-03-13 00:00", "2026-03-20 00:00", "2026-03-27 00:00", "2026-04-03 00:00"}},
+		// A starred field with a step still counts as unrestricted.
+		{"starred day of month with a step", "0 0 */2 * mon", from, []string{"2026-03-09 00:00", "2026-03-23 00:00"}},
+		{"macro", "@monthly", from, []string{"2026-04-01 00:00", "2026-05-01 00:00"}},
+		{"leap day", "0 0 29 2 *", from, []string{"2028-02-29 00:00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			next := tt.from
+			for _, want := range tt.want {
+				next = s.Next(next)
+				if got := next.Format("2006-01-02 15:04"); got != want {
+					t.Fatalf("Next = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNextNever(t *testing.T) {
+	s, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := s.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !next.IsZero() {
+		t.Errorf("Next = %s, want the zero time", next)
+	}
+}
+
+func TestNextDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	santiago, err := time.LoadLocation("America/Santiago")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want []string
+	}{
+		// On 2026-03-08 New York moves from 02:00 EST to 03:00 EDT.
+		{"hourly across the gap", "0 * * * *", time.Date(2026, 3, 8, 0, 30, 0, 0, newYork), []string{"2026-03-08 01:00 EST", "2026-03-08 03:00 EDT", "2026-03-08 04:00 EDT"}},
+		{"time inside the gap is skipped", "30 2 * * *", time.Date(2026, 3, 7, 12, 0, 0, 0, newYork), []string{"2026-03-09 02:30 EDT", "2026-03-10 02:30 EDT"}},
+		{"daily after the gap", "0 6 * * *", time.Date(2026, 3, 7, 12, 0, 0, 0, newYork), []string{"2026-03-08 06:00 EDT", "2026-03-09 06:00 EDT"}},
+		// On 2026-09-06 Santiago moves from 00:00 to 01:00, so that day has no midnight.
+		{"day without midnight", "0 12 * * *", time.Date(2026, 9, 5, 13, 0, 0, 0, santiago), []string{"2026-09-06 12:00 -03", "2026-09-07 12:00 -03"}},
+		{"midnight inside the gap is skipped", "0 0 * * *", time.Date(2026, 9, 5, 13, 0, 0, 0, santiago), []string{"2026-09-07 00:00 -03"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			next := tt.from
+			for _, want := range tt.want {
+				next = s.Next(next)
+				if got := next.Format("2006-01-02 15:04 MST"); got != want {
+					t.Fatalf("Next = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"* * * *", "expected 5 fields"},
+		{"* * * * * *", "expected 5 fields"},
+		{"60 * * * *", "invalid minute"},
+		{"* 24 * * *", "invalid hour"},
+		{"* * 0 * *", "invalid day of month"},
+		{"* * 32 * *", "invalid day of month"},
+		{"* * * 13 *", "invalid month"},
+		{"* * * foo *", "invalid month"},
+		{"* * * * 8", "invalid day of week"},
+		{"* * * * mon-xyz", "invalid day of week"},
+		{"*/0 * * * *", "invalid step"},
+		{"*/x * * * *", "invalid step"},
+		{"30-10 * * * *", "invalid range"},
+		{"@every5m", "expected 5 fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if err == nil {
+				t.Fatalf("Parse(%q) succeeded", tt.expr)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse(%q) = %v, want an error containing %q", tt.expr, err, tt.want)
+			}
+		})
+	}
+}
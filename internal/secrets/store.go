@@ -0,0 +1,165 @@
+// Package secrets keeps credentials in a local file encrypted with a passphrase,
+// so they do not have to be stored in plain text in config.yaml.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// KDFIterations is the PBKDF2-HMAC-SHA256 iteration count used to derive keys from passphrases.
+const KDFIterations = 600000
+
+// maxIterations bounds the iteration count read from a secrets file, so that a
+// corrupt or tampered file cannot make Open derive a key for hours.
+const maxIterations = 10 * KDFIterations
+
+// ErrWrongPassphrase is returned when a secrets file cannot be decrypted.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupt secrets file")
+
+// DeriveKey derives a 256-bit AES key from a passphrase and salt.
+func DeriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return pbkdf2.Key(sha256.New, passphrase, salt, KDFIterations, 32)
+}
+
+// fileFormat is the on-disk layout of a secrets file. Data is the AES-256-GCM
+// encrypted JSON object of all secrets.
+type fileFormat struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// Store is an opened secrets file.
+type Store struct {
+	path       string
+	passphrase string
+	values     map[string]string
+}
+
+// Open decrypts the secrets file at path. A missing file yields an empty store that
+// is created by Save.
+func Open(path, passphrase string) (*Store, error) {
+	s := &Store{path: path, passphrase: passphrase, values: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f fileFormat
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	if f.Version != 1 || f.KDF != "pbkdf2-sha256" {
+		return nil, fmt.Errorf("%s: unsupported secrets file format (version %d, kdf %q)", path, f.Version, f.KDF)
+	}
+	if f.Iterations <= 0 || f.Iterations > maxIterations {
+		return nil, fmt.Errorf("%s: invalid key derivation iteration count %d (expected 1-%d); the file is corrupt", path, f.Iterations, maxIterations)
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, f.Salt, f.Iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	if err := json.Unmarshal(plain, &s.values); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Get returns the secret with the given name.
+func (s *Store) Get(name string) (string, bool) {
+	v, ok := s.values[name]
+	return v, ok
+}
+
+// Set adds or replaces a secret. Call Save to write it.
+func (s *Store) Set(name, value string) {
+	s.values[name] = value
+}
+
+// Delete removes a secret and reports whether it existed. Call Save to write it.
+func (s *Store) Delete(name string) bool {
+	_, ok := s.values[name]
+	delete(s.values, name)
+	return ok
+}
+
+// Names returns the names of all secrets, sorted.
+func (s *Store) Names() []string {
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save encrypts the secrets with a fresh salt and nonce and writes them to the file,
+// readable only by the owner.
+func (s *Store) Save() error {
+	plain, err := json.Marshal(s.values)
+	if err != nil {
+		return err
+	}
+	f := fileFormat{Version: 1, KDF: "pbkdf2-sha256", Iterations: KDFIterations, Salt: make([]byte, 16), Nonce: make([]byte, 12)}
+	if _, err := rand.Read(f.Salt); err != nil {
+		return err
+	}
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	key, err := DeriveKey(s.passphrase, f.Salt)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	f.Data = gcm.Seal(nil, f.Nonce, plain, nil)
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	// فایل موقت و سپس جایگزینی، تا فایل قبلی در صورت خطا خراب نشود
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
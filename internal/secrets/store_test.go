@@ -0,0 +1,102 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSaveOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dir", "secrets.enc")
+	s, err := Open(path, "passphrase")
+	if err != nil {
+		t.Fatalf("opening a missing file: %v", err)
+	}
+	if names := s.Names(); len(names) != 0 {
+		t.Fatalf("a missing file holds %v", names)
+	}
+	s.Set("mongo", "p@ss:word")
+	s.Set("s3", "key")
+	s.Set("old", "x")
+	if !s.Delete("old") || s.Delete("missing") {
+		t.Fatal("Delete does not report whether the secret existed")
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("secrets file has mode %o, want 600", perm)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "p@ss:word") {
+		t.Fatal("the secrets file contains a secret in plain text")
+	}
+
+	opened, err := Open(path, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := opened.Names(); !reflect.DeepEqual(names, []string{"mongo", "s3"}) {
+		t.Errorf("Names() = %v, want [mongo s3]", names)
+	}
+	if v, ok := opened.Get("mongo"); !ok || v != "p@ss:word" {
+		t.Errorf("Get(mongo) = %q, %v", v, ok)
+	}
+
+	if _, err := Open(path, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Open with a wrong passphrase = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestOpenRejectsIterationCounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	s, _ := Open(path, "passphrase")
+	s.Set("mongo", "secret")
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, iterations := range []int{0, -1, maxIterations + 1, 1 << 40} {
+		var f map[string]any
+		if err := json.Unmarshal(data, &f); err != nil {
+			t.Fatal(err)
+		}
+		f["iterations"] = iterations
+		tampered, _ := json.Marshal(f)
+		if err := os.WriteFile(path, tampered, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(path, "passphrase"); err == nil || !strings.Contains(err.Error(), "iteration count") {
+			t.Errorf("iterations %d: Open = %v, want an iteration count error", iterations, err)
+		}
+	}
+}
+
+func TestOpenRejectsUnknownFormats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	for _, content := range []string{"not json", `{"version": 2, "kdf": "pbkdf2-sha256"}`, `{"version": 1, "kdf": "scrypt"}`} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(path, "passphrase"); err == nil {
+			t.Errorf("Open(%q) succeeded", content)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Local stores files in a directory, e.g. a mounted network share.
+type Local struct {
+	Dir string
+}
+
+// NewLocal returns a Local storage for dir.
+func NewLocal(dir string) (*Local, error) {
+	if dir == "" {
+		return nil, errors.New("local storage needs a path")
+	}
+	return &Local{Dir: dir}, nil
+}
+
+func (l *Local) String() string { return l.Dir }
+
+func (l *Local) Put(ctx context.Context, name string, r io.Reader, size int64) error {
+	if err := os.MkdirAll(l.Dir, 0755); err != nil {
+		return err
+	}
+	// نوشتن در فایل موقت و سپس تغییر نام، تا فایل ناقص در مقصد دیده نشود
+	path := filepath.Join(l.Dir, name)
+	f, err := os.Create(path + ".part")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func (l *Local) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(l.Dir, name))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (l *Local) List(ctx context.Context) ([]Object, error) {
+	entries, err := os.ReadDir(l.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var objects []Object
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		objects = append(objects, Object{Name: entry.Name(), Size: info.Size(), ModTime: info.ModTime()})
+	}
+	return objects, nil
+}
+
+func (l *Local) Delete(ctx context.Context, name string) error {
+	return os.Remove(filepath.Join(l.Dir, name))
+}
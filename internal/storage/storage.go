@@ -0,0 +1,85 @@
+// Package storage moves backup archives and manifests to and from remote locations:
+// a directory (e.g. a mounted network share), S3-compatible object storage, SFTP
+// servers and WebDAV servers.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned by Get for objects that do not exist.
+var ErrNotFound = errors.New("object not found")
+
+// Object is a file stored in a backend.
+type Object struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Storage is a flat collection of files, addressed by name.
+type Storage interface {
+	// String describes the location in messages, e.g. "s3://backups/prod".
+	String() string
+	// Put stores size bytes read from r under name, replacing an existing object.
+	Put(ctx context.Context, name string, r io.Reader, size int64) error
+	// Get opens an object for reading. It returns ErrNotFound if it does not exist.
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	// List returns all objects.
+	List(ctx context.Context) ([]Object, error)
+	// Delete removes an object.
+	Delete(ctx context.Context, name string) error
+}
+
+// Config selects and configures a backend. Only the fields of the chosen Type are used.
+type Config struct {
+	// Type is one of "local", "s3", "sftp" or "webdav".
+	Type string
+
+	// Path is the directory for "local" and "sftp".
+	Path string
+
+	// Endpoint, Bucket, Prefix, Region, AccessKey, SecretKey and PathStyle configure "s3".
+	Endpoint  string
+	Bucket    string
+	Prefix    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+
+	// Host, Port, KeyFile, KnownHosts and InsecureIgnoreHostKey configure "sftp".
+	Host                  string
+	Port                  int
+	KeyFile               string
+	KnownHosts            string
+	InsecureIgnoreHostKey bool
+
+	// URL is the collection URL for "webdav".
+	URL string
+
+	// User and Password authenticate "sftp" and "webdav".
+	User     string
+	Password string
+}
+
+// New returns the backend described by cfg.
+func New(cfg Config) (Storage, error) {
+	switch cfg.Type {
+	case "local":
+		return NewLocal(cfg.Path)
+	case "s3":
+		return NewS3(cfg)
+	case "sftp":
+		return NewSFTP(cfg)
+	case "webdav":
+		return NewWebDAV(cfg)
+	case "":
+		return nil, errors.New("no storage type configured")
+	}
+	return nil, fmt.Errorf("unknown storage type %q (supported: local, s3, sftp, webdav)", cfg.Type)
+}
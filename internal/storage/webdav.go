@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebDAV stores files in a WebDAV collection (Nextcloud, ownCloud, Apache mod_dav...).
+type WebDAV struct {
+	base     *url.URL
+	user     string
+	password string
+	client   *http.Client
+}
+
+// NewWebDAV returns a WebDAV storage for the collection at cfg.URL.
+func NewWebDAV(cfg Config) (*WebDAV, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid webdav url %q", cfg.URL)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &WebDAV{base: u, user: cfg.User, password: cfg.Password, client: &http.Client{}}, nil
+}
+
+func (w *WebDAV) String() string {
+	return w.base.Redacted()
+}
+
+func (w *WebDAV) Put(ctx context.Context, name string, r io.Reader, size int64) error {
+	// پوشه مقصد در صورت نبود ساخته می‌شود؛ اگر وجود داشته باشد MKCOL خطای 405 می‌دهد
+	if resp, err := w.do(ctx, "MKCOL", "", nil, -1, nil); err == nil {
+		resp.Body.Close()
+	}
+	resp, err := w.do(ctx, http.MethodPut, name, r, size, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (w *WebDAV) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := w.do(ctx, http.MethodGet, name, nil, -1, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (w *WebDAV) List(ctx context.Context) ([]Object, error) {
+	body := `<?xml version="1.0" encoding="utf-8"?><d:propfind xmlns:d="DAV:"><d:prop><d:getcontentlength/><d:getlastmodified/><d:resourcetype/></d:prop></d:propfind>`
+	resp, err := w.do(ctx, "PROPFIND", "", strings.NewReader(body), int64(len(body)), map[string]string{
+		"Depth":        "1",
+		"Content-Type": "application/xml",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var multistatus struct {
+		Responses []struct {
+			Href     string `xml:"href"`
+			Propstat []struct {
+				Prop struct {
+					ContentLength string `xml:"getcontentlength"`
+					LastModified  string `xml:"getlastmodified"`
+					ResourceType  struct {
+						Collection *struct{} `xml:"collection"`
+					} `xml:"resourcetype"`
+				} `xml:"prop"`
+			} `xml:"propstat"`
+		} `xml:"response"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&multistatus); err != nil {
+		return nil, fmt.Errorf("decoding webdav listing: %w", err)
+	}
+
+	var objects []Object
+	for _, r := range multistatus.Responses {
+		href, err := url.PathUnescape(r.Href)
+		if err != nil {
+			href = r.Href
+		}
+		name := path.Base(strings.TrimSuffix(href, "/"))
+		if len(r.Propstat) == 0 || strings.HasSuffix(href, "/") {
+			continue
+		}
+		prop := r.Propstat[0].Prop
+		if prop.ResourceType.Collection != nil {
+			continue
+		}
+		size, _ := strconv.ParseInt(prop.ContentLength, 10, 64)
+		modTime, _ := time.Parse(http.TimeFormat, prop.LastModified)
+		objects = append(objects, Object{Name: name, Size: size, ModTime: modTime})
+	}
+	return objects, nil
+}
+
+func (w *WebDAV) Delete(ctx context.Context, name string) error {
+	resp, err := w.do(ctx, http.MethodDelete, name, nil, -1, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends a request for name (empty for the collection itself) and returns the
+// response if its status is 2xx.
+func (w *WebDAV) do(ctx context.Context, method, name string, body io.Reader, size int64, headers map[string]string) (*http.Response, error) {
+	u := w.base.JoinPath(name)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if w.user != "" {
+		req.SetBasicAuth(w.user, w.password)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && method == http.MethodGet {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, errors.New("webdav: authentication failed")
+	}
+	return nil, fmt.Errorf("webdav %s %s: %s", method, name, resp.Status)
+}